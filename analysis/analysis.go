@@ -0,0 +1,392 @@
+// Package analysis builds the control flow graphs of the functions in Go
+// source and computes their metrics, findings and DOT renderings. It is the
+// engine of the PDG_Go_AVPB command, whose flags map onto Options.
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// Options control what an analysis computes and reports. The zero value
+// computes every metric and draws plain graphs, but weighs nothing in the
+// Chepin score and reports no complexity findings; DefaultOptions returns
+// the defaults of the command.
+type Options struct {
+	// Metrics names the metrics to compute and print, as listed for the
+	// -metrics flag: cyclomatic, cognitive, chepin, statements, depth,
+	// operators, vocabulary, paths, sccs, blocks, calls and recursion. Nil
+	// selects all of them.
+	Metrics map[string]bool
+	// ChepinP, ChepinM, ChepinC and ChepinT weigh the input, modified,
+	// control and unused variables in the Chepin score.
+	ChepinP, ChepinM, ChepinC, ChepinT float64
+	// Dominators computes the immediate dominator of every block.
+	Dominators bool
+	// MaxComplexity and MaxStatements are the thresholds of the
+	// cyclomatic-complexity and too-many-statements findings; 0 disables
+	// the finding.
+	MaxComplexity, MaxStatements int
+	// Initializers also analyzes the package-level var initializers that
+	// contain calls or function literals, see initializerFuncs.
+	Initializers bool
+	// Select, if not nil, reports whether a function is to be analyzed.
+	Select func(fset *token.FileSet, fn *ast.FuncDecl) bool
+	// Visitors are called on the CFG of every analyzed function, in the
+	// order given, to accumulate custom metrics. The live blocks are
+	// visited in index order, each first with a nil node and then with its
+	// nodes in execution order; unreachable blocks are skipped. The walk is
+	// part of computing the built-in metrics, so it precedes OnResult for
+	// the same function. Visitors are not called with Validate.
+	Visitors []NodeVisitor
+
+	// MetricsOnly computes the metrics without the DOT graph and the
+	// printed blocks.
+	MetricsOnly bool
+	// The graph options have the meaning of the command's flags of the
+	// same names.
+	OrderNodes    bool   // -order
+	BlockNodes    bool   // -block-nodes
+	BranchLabels  bool   // -branch-labels
+	DefUse        bool   // -def-use
+	DefUseLabels  bool   // -def-use-labels
+	HTMLLabels    bool   // -html-labels
+	Probabilities bool   // -probabilities
+	MaxSelector   int    // -max-selector
+	ColorScheme   string // -color-scheme, one of ColorSchemes; "" is "default"
+	IfEdges       string // -if-edges, one of IfRenderings; "" is "cfg"
+
+	// AST records the syntax tree of each function in Result.AST.
+	AST bool
+	// ASCII records the ASCII rendering of each CFG in Result.ASCII.
+	ASCII bool
+	// Split records each graph split into per-loop and per-branch
+	// sub-graphs in Result.SplitGraphs.
+	Split bool
+	// Validate only renders the node labels, collecting the nodes without
+	// a rendering in Unhandled; the results hold the function names only.
+	Validate bool
+
+	// Text, if not nil, receives the text report: the syntax tree of each
+	// file and, for each function, its blocks, metrics, findings and DOT
+	// graph.
+	Text io.Writer
+	// Log, if not nil, receives the syntax errors the parser recovered
+	// from. The functions skipped because of them are noted in Text, or in
+	// Log without a text report.
+	Log io.Writer
+	// OnResult, if not nil, is called with each result as soon as its
+	// function has been analyzed.
+	OnResult func(*Result)
+	// OnFile, if not nil, is called with the metrics of each file once its
+	// functions have been analyzed; the text report then includes them.
+	OnFile func(FileMetrics)
+	// Unhandled, if not nil, collects the nodes that had no rendering.
+	Unhandled UnhandledNodes
+	// Profile, if not nil, accumulates the time spent in each of Phases.
+	Profile Profile
+}
+
+// DefaultOptions returns the options of the command run without flags.
+func DefaultOptions() Options {
+	return Options{
+		ChepinP:       1,
+		ChepinM:       2,
+		ChepinC:       3,
+		ChepinT:       0.5,
+		MaxComplexity: 10,
+		ColorScheme:   "default",
+		IfEdges:       "cfg",
+	}
+}
+
+// analyzer runs an analysis with fixed options. The methods that render
+// labels also work on a nil analyzer, as with the zero Options.
+type analyzer struct {
+	opts Options
+	fset *token.FileSet // of the function being rendered, for Unhandled
+}
+
+// wantMetric reports whether the metric name is to be computed and printed.
+func (a *analyzer) wantMetric(name string) bool {
+	return a.opts.Metrics == nil || a.opts.Metrics[name]
+}
+
+// textf writes to the text report, if there is one.
+func (a *analyzer) textf(format string, args ...any) {
+	if a.opts.Text != nil {
+		fmt.Fprintf(a.opts.Text, format, args...)
+	}
+}
+
+// SampleSource is the program the command analyzes when no other input is
+// given: a function with nested loops, branches and early exits.
+const SampleSource = `
+package main
+
+func complexFunction() int {
+	a := 0
+	b := 1
+	c := 3
+	n := 4
+	result := 0
+	sum := 0
+
+	for i := 0; i < n; i++ {
+		if с > 2 {
+			a += i
+		} else {
+			b += i
+		}
+
+		for j := 0; j < i; j++ {
+			if j < 3 {
+				c += j
+			} else {
+				sum += j
+			}
+		}
+
+		if a > b {
+			continue
+		} else if b > c {
+			break
+		}
+	}
+	if sum > 10 {
+		result = a + b
+		return result
+	} else {
+		return c
+	}
+}
+`
+
+// snippetPrefix and snippetSuffix wrap statements into a function of a
+// compilable file. The //line directive makes positions, and thus parse
+// errors, refer to the lines of the snippet itself.
+const (
+	snippetPrefix = "package main\n\nfunc _() {\n//line snippet.go:1:1\n"
+	snippetSuffix = "\n}\n"
+)
+
+// SnippetSource returns the source of a file named snippet.go whose only
+// function, _, has the statements in code as its body, as analyzed by the
+// command's -e flag. Positions in the file refer to the lines of code.
+func SnippetSource(code string) string {
+	return snippetPrefix + code + snippetSuffix
+}
+
+// AnalyzeReader reads a Go source file from r and analyzes every function in
+// it. filename is used in positions and error messages only.
+func AnalyzeReader(r io.Reader, filename string, opts Options) ([]*Result, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeSource(context.Background(), filename, string(src), opts)
+}
+
+// AnalyzeSource parses src as the file filename and analyzes its functions
+// until ctx is done. Syntax errors are written to opts.Log; the parser
+// recovers from them and the functions free of errors are still analyzed.
+// A file without a valid package clause is not Go at all and is an error.
+func AnalyzeSource(ctx context.Context, filename, src string, opts Options) ([]*Result, error) {
+	a := &analyzer{opts: opts}
+	fset := token.NewFileSet()
+	start := time.Now()
+	node, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.AllErrors)
+	a.timePhase("parse", start)
+	var syntaxErrors scanner.ErrorList
+	if err != nil {
+		if !errors.As(err, &syntaxErrors) || node == nil || !node.Package.IsValid() {
+			return nil, err
+		}
+		if opts.Log != nil {
+			for _, e := range syntaxErrors {
+				fmt.Fprintf(opts.Log, "Syntax error: %s\n", e)
+			}
+		}
+	}
+
+	if opts.Text != nil && !opts.MetricsOnly {
+		ast.Fprint(opts.Text, fset, node, ast.NotNilFilter)
+		a.textf("\n-------------------\n")
+	}
+	return a.analyzeFile(ctx, fset, node, nil, syntaxErrors), nil
+}
+
+// AnalyzeFile analyzes the functions of file, parsed into fset, until ctx
+// is done. info may be nil when no type information is available.
+func AnalyzeFile(ctx context.Context, fset *token.FileSet, file *ast.File, info *types.Info, opts Options) []*Result {
+	a := &analyzer{opts: opts}
+	return a.analyzeFile(ctx, fset, file, info, nil)
+}
+
+// analyzeFile analyzes the functions of file, skipping those containing one
+// of syntaxErrors.
+func (a *analyzer) analyzeFile(ctx context.Context, fset *token.FileSet, file *ast.File, info *types.Info, syntaxErrors scanner.ErrorList) []*Result {
+	selected := func(fn *ast.FuncDecl) bool {
+		return a.opts.Select == nil || a.opts.Select(fset, fn)
+	}
+	var results []*Result
+	for _, decl := range file.Decls {
+		if ctx.Err() != nil {
+			return results
+		}
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			if containsError(fset, fn, syntaxErrors) {
+				a.skipped(fn)
+				continue
+			}
+			if fn.Body != nil && selected(fn) {
+				results = append(results, a.analyzeFunc(ctx, fset, file, fn, info))
+			}
+		}
+	}
+	if a.opts.Initializers {
+		for _, fn := range initializerFuncs(file) {
+			if ctx.Err() == nil && !containsError(fset, fn, syntaxErrors) && selected(fn) {
+				results = append(results, a.analyzeFunc(ctx, fset, file, fn, info))
+			}
+		}
+	}
+	if a.opts.OnFile != nil {
+		a.recordFile(fset, file, results)
+	}
+	return results
+}
+
+// skipped notes that fn is not analyzed because of a syntax error.
+func (a *analyzer) skipped(fn *ast.FuncDecl) {
+	w := a.opts.Text
+	if w == nil {
+		w = a.opts.Log
+	}
+	if w != nil {
+		fmt.Fprintf(w, "Skipped (syntax errors): %s\n", funcName(fn))
+	}
+}
+
+// analyzeFunc builds the CFG of fn, declared in file, and returns its
+// metrics and findings, writing the text report of fn if there is one. info
+// may be nil when no type information is available.
+func (a *analyzer) analyzeFunc(ctx context.Context, fset *token.FileSet, file *ast.File, fn *ast.FuncDecl, info *types.Info) *Result {
+	start := time.Now()
+	predicate := func(*ast.CallExpr) bool { return true }
+	cg := cfg.New(fn.Body, predicate)
+	a.timePhase("cfg", start)
+
+	start = time.Now()
+	dotFmt, chepin := a.genDot(fset, fn, cg, info)
+	if a.opts.Validate {
+		// genDot has recorded the unhandled nodes; nothing else is needed.
+		return &Result{Metrics: Metrics{Kind: "function", Name: funcName(fn)}}
+	}
+	if a.opts.OrderNodes && !a.opts.MetricsOnly {
+		dotFmt = numberNodes(cg, dotFmt)
+	}
+	var splitGraphs map[string]string
+	if a.opts.Split && !a.opts.MetricsOnly {
+		splitGraphs = a.splitDot(fn, cg, dotFmt)
+	}
+	// The ASCII rendering reads the plain labels of the statement nodes.
+	labelDot := dotFmt
+	if a.opts.BlockNodes && !a.opts.MetricsOnly {
+		dotFmt = a.blockDot(cg, dotFmt)
+	}
+	if a.opts.HTMLLabels && !a.opts.MetricsOnly && !a.opts.ASCII {
+		dotFmt = a.htmlLabelDot(dotFmt)
+	}
+	a.timePhase("dot", start)
+
+	start = time.Now()
+	result := &Result{
+		Metrics:     a.computeMetrics(ctx, fset, file, fn, cg, info, chepin),
+		Findings:    a.checkFunc(fset, file, fn, cg),
+		Dot:         dotFmt,
+		Callees:     calleeNames(file, fn),
+		SplitGraphs: splitGraphs,
+	}
+	a.timePhase("metrics", start)
+	if a.opts.AST {
+		result.AST = astTree(fset, fn)
+	}
+	if a.opts.ASCII {
+		result.ASCII = asciiCFG(cg, labelDot)
+	}
+	if a.opts.OnResult != nil {
+		a.opts.OnResult(result)
+	}
+	if a.opts.Text == nil {
+		return result
+	}
+
+	w := a.opts.Text
+	metrics := result.Metrics
+	fmt.Fprintf(w, "CFG for function: %s\n", funcName(fn))
+	if !a.opts.MetricsOnly {
+		a.printCFG(w, cg)
+	}
+	if a.wantMetric("chepin") {
+		a.printChepin(w, chepin)
+	}
+	if a.wantMetric("cyclomatic") {
+		printCyclomatic(w, cg)
+		printCyclomaticComparison(w, fset, fn, cg)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	if a.wantMetric("cognitive") {
+		fmt.Fprintln(w, "Cognitive Complexity: ", metrics.Cognitive)
+	}
+	if a.wantMetric("statements") {
+		fmt.Fprintln(w, "Statements: ", metrics.Statements)
+		fmt.Fprintf(w, "Control-flow ratio: %.2f\n", metrics.ControlFlowRatio)
+	}
+	if depth, pos := maxExpressionDepth(fn); depth > 0 && a.wantMetric("depth") {
+		fmt.Fprintf(w, "Max Expression Depth: %d (at %s).\n", depth, fset.Position(pos))
+	}
+	if a.wantMetric("operators") {
+		fmt.Fprintf(w, "Distinct operators: %d (%s)\n", metrics.DistinctOps, strings.Join(metrics.Operators, " "))
+	}
+	if a.wantMetric("vocabulary") {
+		fmt.Fprintf(w, "Variable vocabulary: %d\n", metrics.Vocabulary)
+	}
+	if a.wantMetric("blocks") {
+		printBlockSizes(w, cg)
+	}
+	if a.wantMetric("paths") {
+		printBasisPaths(ctx, w, cg)
+	}
+	if a.wantMetric("sccs") {
+		printComponents(w, cg)
+	}
+	if a.wantMetric("calls") {
+		printCallCategories(w, metrics.Calls, metrics.Conversions)
+	}
+	if a.wantMetric("recursion") {
+		printRecursion(w, fset, recursiveCalls(fn, info))
+	}
+	if a.opts.Dominators {
+		printDominators(w, metrics.Dominators)
+	}
+	printFindings(w, result.Findings)
+	if a.opts.MetricsOnly {
+		return result
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintln(w, "DOT Format:")
+	fmt.Fprintln(w, dotFmt)
+	return result
+}
@@ -0,0 +1,137 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWantMetric(t *testing.T) {
+	tests := []struct {
+		metrics map[string]bool
+		want    map[string]bool
+	}{
+		{nil, map[string]bool{"cyclomatic": true, "chepin": true}},
+		{map[string]bool{"cyclomatic": true}, map[string]bool{"cyclomatic": true, "chepin": false}},
+	}
+	for _, test := range tests {
+		a := &analyzer{opts: Options{Metrics: test.metrics}}
+		for name, want := range test.want {
+			if got := a.wantMetric(name); got != want {
+				t.Errorf("wantMetric(%q) with Metrics %v = %v, want %v", name, test.metrics, got, want)
+			}
+		}
+	}
+}
+
+// failingReader fails every read with err.
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestAnalyzeReader(t *testing.T) {
+	readErr := errors.New("read failed")
+	tests := []struct {
+		name    string
+		r       io.Reader
+		want    []string // function names
+		wantErr bool
+	}{
+		{"source", strings.NewReader("package p\n\nfunc A() {}\n\nfunc (T) B() {}\n"), []string{"A", "T.B"}, false},
+		{"no functions", strings.NewReader("package p\n"), nil, false},
+		{"not Go", strings.NewReader("hello"), nil, true},
+		{"read error", failingReader{readErr}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := AnalyzeReader(test.r, "p.go", DefaultOptions())
+			if (err != nil) != test.wantErr {
+				t.Fatalf("AnalyzeReader error %v, want error %v", err, test.wantErr)
+			}
+			if r, ok := test.r.(failingReader); ok && !errors.Is(err, r.err) {
+				t.Errorf("error %v does not wrap the read error", err)
+			}
+			var names []string
+			for _, result := range results {
+				names = append(names, result.Metrics.Name)
+				if result.Metrics.File != "p.go" {
+					t.Errorf("%s is in file %q, want p.go", result.Metrics.Name, result.Metrics.File)
+				}
+			}
+			if !slices.Equal(names, test.want) {
+				t.Errorf("analyzed %v, want %v", names, test.want)
+			}
+		})
+	}
+}
+
+// sampleMetrics analyzes SampleSource with opts and returns the metrics of
+// its function.
+func sampleMetrics(t *testing.T, opts Options) Metrics {
+	t.Helper()
+	results, err := AnalyzeSource(context.Background(), "example.go", SampleSource, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("%d functions in the sample, want 1", len(results))
+	}
+	return results[0].Metrics
+}
+
+func TestSampleOperators(t *testing.T) {
+	m := sampleMetrics(t, DefaultOptions())
+	want := []string{"+", "++", "+=", ":=", "<", "=", ">", "break", "continue", "else", "for", "if", "return"}
+	if !slices.Equal(m.Operators, want) || m.DistinctOps != len(want) {
+		t.Errorf("operators %v (%d distinct), want %v", m.Operators, m.DistinctOps, want)
+	}
+}
+
+// TestSampleComponents checks that the nested loops of the sample form a
+// single component, and that the entry, the outer loop's exit and the
+// blocks after it are components of their own.
+func TestSampleComponents(t *testing.T) {
+	m := sampleMetrics(t, DefaultOptions())
+	if m.Components != 6 || !slices.Equal(m.LoopComponents, []int{17}) {
+		t.Errorf("%d components with loops of sizes %v, want 6 with [17]", m.Components, m.LoopComponents)
+	}
+}
+
+// TestSampleDominators checks the immediate dominators of the sample's
+// blocks with Options.Dominators. The inner loop is entered only after the
+// outer loop's first if-else, and the returns only after the outer loop
+// exits.
+func TestSampleDominators(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Dominators = true
+	want := map[int32]int32{
+		1:  3,  // outer loop body
+		2:  3,  // outer loop done
+		3:  0,  // outer loop condition
+		4:  9,  // i++
+		5:  1,  // first if-else: then
+		6:  1,  // first if-else: done
+		7:  1,  // first if-else: else
+		8:  10, // inner loop body
+		9:  10, // inner loop done
+		10: 6,  // inner loop condition
+		11: 13, // j++
+		12: 8,  // inner if-else: then
+		13: 8,  // inner if-else: done
+		14: 8,  // inner if-else: else
+		15: 9,  // continue
+		16: 20, // if a > b: done
+		17: 9,  // else if b > c
+		19: 17, // break
+		20: 17, // else if b > c: done
+		22: 2,  // return result
+		24: 2,  // return c
+	}
+	if got := sampleMetrics(t, opts).Dominators; !maps.Equal(got, want) {
+		t.Errorf("immediate dominators %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// dotNodeLabel matches a node statement of the DOT output of genDot.
+var dotNodeLabel = regexp.MustCompile(`(?m)^  (block_\d+_node_\d+) \[label="((?:[^"\\]|\\.)*)"\];$`)
+
+// dotLabels returns the unescaped node labels of the DOT output of genDot
+// by node ID.
+func dotLabels(dot string) map[string]string {
+	labels := make(map[string]string)
+	for _, match := range dotNodeLabel.FindAllStringSubmatch(dot, -1) {
+		labels[match[1]] = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(match[2])
+	}
+	return labels
+}
+
+// asciiCFG renders cg as an indented tree for terminals without Graphviz,
+// using the node labels of its DOT rendering dot. Blocks are listed in
+// depth-first order from the entry, each with its nodes followed by its
+// successors. A successor already printed is not expanded again: an edge
+// back to a block still being expanded (a loop) is marked with ↺, an edge
+// joining a block printed earlier with →.
+func asciiCFG(cg *cfg.CFG, dot string) string {
+	labels := dotLabels(dot)
+	var sb strings.Builder
+	printed := make(map[int32]bool)
+	onPath := make(map[int32]bool)
+	var visit func(block *cfg.Block, prefix string)
+	visit = func(block *cfg.Block, prefix string) {
+		printed[block.Index] = true
+		onPath[block.Index] = true
+		for i := range block.Nodes {
+			fmt.Fprintf(&sb, "%s│ %s\n", prefix, labels[fmt.Sprintf("block_%d_node_%d", block.Index, i)])
+		}
+		for i, succ := range block.Succs {
+			branch, indent := "├─", "│   "
+			if i == len(block.Succs)-1 {
+				branch, indent = "└─", "    "
+			}
+			switch {
+			case onPath[succ.Index]:
+				fmt.Fprintf(&sb, "%s%s↺ [%d] %s (loop)\n", prefix, branch, succ.Index, succ.Kind)
+			case printed[succ.Index]:
+				fmt.Fprintf(&sb, "%s%s→ [%d] %s\n", prefix, branch, succ.Index, succ.Kind)
+			default:
+				fmt.Fprintf(&sb, "%s%s▶ [%d] %s\n", prefix, branch, succ.Index, succ.Kind)
+				visit(succ, prefix+indent)
+			}
+		}
+		onPath[block.Index] = false
+	}
+	if len(cg.Blocks) > 0 {
+		entry := cg.Blocks[0]
+		fmt.Fprintf(&sb, "[%d] %s\n", entry.Index, entry.Kind)
+		visit(entry, "")
+	}
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestASCIIOutput(t *testing.T) {
+	src := SnippetSource("a := 1\nif a > 0 {\n\tx()\n}")
+	tests := []struct {
+		name         string
+		blocks, html bool
+	}{
+		{"statement nodes", false, false},
+		{"block nodes", true, false},
+		{"HTML labels", false, true},
+		{"block nodes and HTML labels", true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.ASCII, opts.BlockNodes, opts.HTMLLabels = true, test.blocks, test.html
+			results, err := AnalyzeSource(context.Background(), "snippet.go", src, opts)
+			if err != nil || len(results) != 1 {
+				t.Fatalf("AnalyzeSource: %d results, error %v", len(results), err)
+			}
+			out := results[0].ASCII + "\n"
+			for _, want := range []string{"[0] Body\n", "│ a = 1\n", "│ a > 0\n", "▶ [1] IfThen\n", "│ x()\n"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("no %q in\n%s", want, out)
+				}
+			}
+			if strings.Contains(out, "│ \n") {
+				t.Errorf("empty labels in\n%s", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+// ASTNode is the -format ast-json serialization of a syntax tree node: its
+// Go type without the package, e.g. "IfStmt", its source range, and its
+// fields by name. A field holds a nested ASTNode, a list of them, or the
+// text of a scalar such as an identifier name, a literal value or an
+// operator. Nil and empty fields, comments and the deprecated ast.Object
+// resolution data are left out.
+type ASTNode struct {
+	Type   string         `json:"type"`
+	Pos    string         `json:"pos"`
+	End    string         `json:"end"`
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+var (
+	nodeType        = reflect.TypeOf((*ast.Node)(nil)).Elem()
+	tokenType       = reflect.TypeOf(token.ILLEGAL)
+	posType         = reflect.TypeOf(token.NoPos)
+	objectType      = reflect.TypeOf((*ast.Object)(nil))
+	scopeType       = reflect.TypeOf((*ast.Scope)(nil))
+	commentsType    = reflect.TypeOf((*ast.CommentGroup)(nil))
+	commentListType = reflect.TypeOf([]*ast.CommentGroup(nil))
+)
+
+// astTree converts the tree rooted at node to its ASTNode form.
+func astTree(fset *token.FileSet, node ast.Node) *ASTNode {
+	v := reflect.ValueOf(node)
+	if !v.IsValid() || v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	tree := &ASTNode{
+		Type: reflect.Indirect(v).Type().Name(),
+		Pos:  fset.Position(node.Pos()).String(),
+		End:  fset.Position(node.End()).String(),
+	}
+	s := reflect.Indirect(v)
+	for i := 0; i < s.NumField(); i++ {
+		field, value := s.Type().Field(i), s.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if encoded := astField(fset, value); encoded != nil {
+			if tree.Fields == nil {
+				tree.Fields = make(map[string]any)
+			}
+			tree.Fields[field.Name] = encoded
+		}
+	}
+	return tree
+}
+
+// astField encodes one field value of a node, returning nil for values that
+// are left out.
+func astField(fset *token.FileSet, value reflect.Value) any {
+	switch value.Type() {
+	case posType, objectType, scopeType, commentsType, commentListType:
+		return nil
+	case tokenType:
+		return value.Interface().(token.Token).String()
+	}
+	switch value.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if value.IsNil() || !value.Type().Implements(nodeType) {
+			return nil
+		}
+		return astTree(fset, value.Interface().(ast.Node))
+	case reflect.Slice:
+		if value.Len() == 0 || !value.Type().Elem().Implements(nodeType) {
+			return nil
+		}
+		list := make([]*ASTNode, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			list = append(list, astTree(fset, value.Index(i).Interface().(ast.Node)))
+		}
+		return list
+	case reflect.String:
+		if value.String() == "" {
+			return nil
+		}
+		return value.String()
+	case reflect.Bool:
+		if !value.Bool() {
+			return nil
+		}
+		return true
+	case reflect.Int:
+		// ast.ChanDir
+		if value.Int() == 0 {
+			return nil
+		}
+		return value.Int()
+	}
+	return nil
+}
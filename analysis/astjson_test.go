@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// compactAST renders tree as Type{Field: value ...} with the fields sorted
+// and positions left out.
+func compactAST(tree any) string {
+	switch n := tree.(type) {
+	case *ASTNode:
+		if n == nil {
+			return "nil"
+		}
+		var fields []string
+		for name, value := range n.Fields {
+			fields = append(fields, name+": "+compactAST(value))
+		}
+		sort.Strings(fields)
+		return n.Type + "{" + strings.Join(fields, " ") + "}"
+	case []*ASTNode:
+		var items []string
+		for _, item := range n {
+			items = append(items, compactAST(item))
+		}
+		return "[" + strings.Join(items, " ") + "]"
+	}
+	return fmt.Sprint(tree)
+}
+
+func TestASTTree(t *testing.T) {
+	tests := []struct {
+		stmt, want string
+	}{
+		{"x := a + 1", "AssignStmt{Lhs: [Ident{Name: x}] Rhs: [BinaryExpr{Op: + X: Ident{Name: a} Y: BasicLit{Kind: INT Value: 1}}] Tok: :=}"},
+		{"f()", "ExprStmt{X: CallExpr{Fun: Ident{Name: f}}}"},
+		{"f(xs...)", "ExprStmt{X: CallExpr{Args: [Ident{Name: xs}] Fun: Ident{Name: f}}}"},
+		{"if ok {\n\treturn\n}", "IfStmt{Body: BlockStmt{List: [ReturnStmt{}]} Cond: Ident{Name: ok}}"},
+		{"var c chan<- int", "DeclStmt{Decl: GenDecl{Specs: [ValueSpec{Names: [Ident{Name: c}] Type: ChanType{Dir: 1 Value: Ident{Name: int}}}] Tok: var}}"},
+		{"// comment\ni++", "IncDecStmt{Tok: ++ X: Ident{Name: i}}"},
+	}
+	for _, test := range tests {
+		fset, _, fn := parseSnippet(t, test.stmt)
+		if got := compactAST(astTree(fset, fn.Body.List[0])); got != test.want {
+			t.Errorf("astTree(%q) =\n%s\nwant\n%s", test.stmt, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+)
+
+// BenchmarkAnalyze compares the full analysis of the sample program with
+// the MetricsOnly fast path.
+func BenchmarkAnalyze(b *testing.B) {
+	// Without Options.Text the timings leave out the text report.
+	for _, mode := range []struct {
+		name        string
+		metricsOnly bool
+	}{{"full", false}, {"metrics-only", true}} {
+		b.Run(mode.name, func(b *testing.B) {
+			opts := DefaultOptions()
+			opts.MetricsOnly = mode.metricsOnly
+			for range b.N {
+				if _, err := AnalyzeSource(context.Background(), "sample.go", SampleSource, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
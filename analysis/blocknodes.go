@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// recordEscaper escapes the characters that structure a DOT record label.
+var recordEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `{`, `\{`, `}`, `\}`, `|`, `\|`, `<`, `\<`, `>`, `\>`)
+
+// blockDot renders cg with one record-shaped DOT node per basic block,
+// listing the labels of its nodes, taken from the statement-level graph dot,
+// as left-aligned lines, and edges between blocks instead of statements.
+func (a *analyzer) blockDot(cg *cfg.CFG, dot string) string {
+	labels := dotLabels(dot)
+	var sb strings.Builder
+	sb.WriteString("digraph G {\n  node [shape=record];\n")
+	sb.WriteString(a.colors().nodeDefaults())
+	for _, block := range cg.Blocks {
+		if !block.Live {
+			continue
+		}
+		lines := []string{}
+		for i := range block.Nodes {
+			lines = append(lines, recordEscaper.Replace(labels[fmt.Sprintf("block_%d_node_%d", block.Index, i)])+`\l`)
+		}
+		label := fmt.Sprintf("%d: %s", block.Index, block.Kind)
+		if len(lines) > 0 {
+			label += "|" + strings.Join(lines, "")
+		}
+		fmt.Fprintf(&sb, "  block_%d [label=\"{%s}\"];\n", block.Index, label)
+		for _, succ := range block.Succs {
+			color := a.colors().branchColor(succ.Kind)
+			if entersDefault(succ) {
+				color = a.colors().otherwise
+			}
+			fmt.Fprintf(&sb, "  block_%d -> block_%d [color=\"%s\"%s];\n", block.Index, succ.Index, color, a.probabilityAttrs(cg, block, succ))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
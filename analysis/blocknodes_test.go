@@ -0,0 +1,29 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBlockDot(t *testing.T) {
+	fset, _, fn := parseSnippet(t, "a := 1\nif a > 0 {\n\tm := map[string]int{}\n\t_ = m\n}\nx()")
+	cg := newCFG(fn)
+	dot, _ := defaultAnalyzer().genDot(fset, fn, cg, nil)
+	got := defaultAnalyzer().blockDot(cg, dot)
+	for _, want := range []string{
+		"node [shape=record];",
+		`block_0 [label="{0: Body|a = 1\la \> 0\l}"];`,
+		`block_1 [label="{1: IfThen|m = map[string]int\{\}\l_ = m\l}"];`,
+		`block_2 [label="{2: IfDone|x()\lReturn: \l}"];`,
+		"block_0 -> block_1",
+		"block_0 -> block_2",
+		"block_1 -> block_2",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("no %s in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "_node_") {
+		t.Errorf("statement nodes in the block graph:\n%s", got)
+	}
+}
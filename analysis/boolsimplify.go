@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// simplifiableBools reports boolean expressions of fn that have a simpler
+// equivalent:
+//
+//	x == true, x != false    x
+//	x == false, x != true    !x
+//	!!x                      x
+//	if c { return true } else { return false }    return c
+//
+// The predeclared true and false are recognized by name.
+func simplifiableBools(fset *token.FileSet, fn *ast.FuncDecl) []Finding {
+	var findings []Finding
+	report := func(node ast.Node, simpler string) {
+		findings = append(findings, newFinding(fset, node, "simplifiable-bool", "note",
+			fmt.Sprintf("%q can be simplified to %q", nodeString(node), simpler)))
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.BinaryExpr:
+			if e.Op != token.EQL && e.Op != token.NEQ {
+				break
+			}
+			operand, value, ok := boolComparison(e)
+			if !ok {
+				break
+			}
+			if value == (e.Op == token.EQL) {
+				report(e, getValue(operand))
+			} else {
+				report(e, negated(operand))
+			}
+		case *ast.UnaryExpr:
+			if inner, ok := ast.Unparen(e.X).(*ast.UnaryExpr); ok && e.Op == token.NOT && inner.Op == token.NOT {
+				report(e, getValue(inner.X))
+				// The inner negation is part of this finding.
+				return false
+			}
+		case *ast.IfStmt:
+			if e.Init != nil {
+				break
+			}
+			then, ok1 := returnedBool(e.Body)
+			otherwise, ok2 := returnedBool(e.Else)
+			if ok1 && ok2 && then != otherwise {
+				if then {
+					report(e, "return "+getValue(e.Cond))
+				} else {
+					report(e, "return "+negated(e.Cond))
+				}
+			}
+		}
+		return true
+	})
+	return findings
+}
+
+// boolComparison returns the other operand of a comparison with the constant
+// true or false, and that constant.
+func boolComparison(e *ast.BinaryExpr) (operand ast.Expr, value bool, ok bool) {
+	if b, ok := boolConst(e.Y); ok {
+		return e.X, b, true
+	}
+	if b, ok := boolConst(e.X); ok {
+		return e.Y, b, true
+	}
+	return nil, false, false
+}
+
+func boolConst(expr ast.Expr) (value, ok bool) {
+	if ident, isIdent := ast.Unparen(expr).(*ast.Ident); isIdent {
+		switch ident.Name {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// returnedBool reports the constant returned by a block consisting of a
+// single "return true" or "return false".
+func returnedBool(stmt ast.Stmt) (value, ok bool) {
+	block, isBlock := stmt.(*ast.BlockStmt)
+	if !isBlock || len(block.List) != 1 {
+		return false, false
+	}
+	ret, isReturn := block.List[0].(*ast.ReturnStmt)
+	if !isReturn || len(ret.Results) != 1 {
+		return false, false
+	}
+	return boolConst(ret.Results[0])
+}
+
+// negated renders the negation of expr, parenthesizing it unless it is an
+// operand that binds tighter than the ! operator.
+func negated(expr ast.Expr) string {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident, *ast.CallExpr, *ast.SelectorExpr, *ast.IndexExpr:
+		return "!" + getValue(e)
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			return getValue(e.X)
+		}
+	}
+	return "!(" + getValue(ast.Unparen(expr)) + ")"
+}
+
+// nodeString renders the expression or, for an if statement, the header of
+// the node reported by simplifiableBools.
+func nodeString(node ast.Node) string {
+	if ifStmt, ok := node.(*ast.IfStmt); ok {
+		return "if " + getValue(ifStmt.Cond) + " { return ... } else { return ... }"
+	}
+	return getValue(node.(ast.Expr))
+}
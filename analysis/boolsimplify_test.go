@@ -0,0 +1,40 @@
+package analysis
+
+import "testing"
+
+func TestSimplifiableBools(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []string // messages
+	}{
+		{"equals true", "if ok == true {\n}", []string{`"ok == true" can be simplified to "ok"`}},
+		{"true on the left", "if true == ok {\n}", []string{`"true == ok" can be simplified to "ok"`}},
+		{"equals false", "if ok == false {\n}", []string{`"ok == false" can be simplified to "!ok"`}},
+		{"not equals true", "x := f() != true", []string{`"f() != true" can be simplified to "!f()"`}},
+		{"not equals false", "if a < b != false {\n}", []string{`"a < b != false" can be simplified to "a < b"`}},
+		{"negated comparison", "if a < b == false {\n}", []string{`"a < b == false" can be simplified to "!(a < b)"`}},
+		{"double negation", "if !!ok {\n}", []string{`"!!ok" can be simplified to "ok"`}},
+		{"return condition", "if a > b {\n\treturn true\n} else {\n\treturn false\n}",
+			[]string{`"if a > b { return ... } else { return ... }" can be simplified to "return a > b"`}},
+		{"return negation", "if ok {\n\treturn false\n} else {\n\treturn true\n}",
+			[]string{`"if ok { return ... } else { return ... }" can be simplified to "return !ok"`}},
+		{"same constant", "if ok {\n\treturn true\n} else {\n\treturn true\n}", nil},
+		{"init statement", "if ok := f(); ok {\n\treturn true\n} else {\n\treturn false\n}", nil},
+		{"other comparison", "if x == 1 {\n}", nil},
+		{"single negation", "if !ok {\n}", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			findings := simplifiableBools(fset, fn)
+			if len(findings) != len(test.want) {
+				t.Fatalf("got %d findings %v, want %v", len(findings), findings, test.want)
+			}
+			for i, finding := range findings {
+				if finding.Message != test.want[i] {
+					t.Errorf("finding %d: %s, want %s", i, finding.Message, test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// branchTarget returns the statement that the break or continue statement
+// branch in fn transfers control to: the innermost enclosing loop for
+// continue, the innermost loop, switch or select for break, or the enclosing
+// statement carrying the branch's label. It returns nil when there is no
+// valid target, such as for a continue outside of any loop.
+func branchTarget(fn *ast.FuncDecl, branch *ast.BranchStmt) ast.Stmt {
+	var stack []ast.Node
+	var target ast.Stmt
+	found := false
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch {
+		case found:
+			return false
+		case n == nil:
+			stack = stack[:len(stack)-1]
+			return true
+		case n == ast.Node(branch):
+			target, found = enclosingTarget(stack, branch), true
+			return false
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return target
+}
+
+// enclosingTarget finds the target of branch among the enclosing nodes on
+// stack, innermost last. A function literal ends the search: a branch
+// cannot leave it.
+func enclosingTarget(stack []ast.Node, branch *ast.BranchStmt) ast.Stmt {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.FuncLit:
+			return nil
+		case *ast.ForStmt, *ast.RangeStmt:
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			if branch.Tok != token.BREAK {
+				continue
+			}
+		default:
+			continue
+		}
+		if branch.Label == nil {
+			return stack[i].(ast.Stmt)
+		}
+		if i > 0 {
+			if labeled, ok := stack[i-1].(*ast.LabeledStmt); ok && labeled.Label.Name == branch.Label.Name {
+				return stack[i].(ast.Stmt)
+			}
+		}
+	}
+	return nil
+}
+
+// branchTargetNode returns the ID of the first node reached when a branch
+// with token tok leaves or restarts target: the node after the construct
+// for break, the post statement or loop head for continue. It returns ""
+// if no such node exists.
+func branchTargetNode(cg *cfg.CFG, target ast.Stmt, tok token.Token) string {
+	kinds := []cfg.BlockKind{cfg.KindForDone, cfg.KindRangeDone, cfg.KindSwitchDone, cfg.KindSelectDone}
+	if tok == token.CONTINUE {
+		kinds = []cfg.BlockKind{cfg.KindForPost, cfg.KindForLoop, cfg.KindRangeLoop}
+	}
+	for _, kind := range kinds {
+		for _, block := range cg.Blocks {
+			if block.Live && block.Stmt == target && block.Kind == kind {
+				if id := entryNodeID(cg, int(block.Index)); id != "" {
+					return id
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// fallsThrough reports whether block is the body of a switch case ending in
+// a fallthrough statement. go/cfg gives such a block the next case's body
+// as its only successor.
+func fallsThrough(block *cfg.Block) bool {
+	clause, ok := block.Stmt.(*ast.CaseClause)
+	if !ok || block.Kind != cfg.KindSwitchCaseBody || len(clause.Body) == 0 {
+		return false
+	}
+	last, ok := clause.Body[len(clause.Body)-1].(*ast.BranchStmt)
+	return ok && last.Tok == token.FALLTHROUGH
+}
+
+// branchVia returns the break, continue or goto statement that makes up the
+// whole of the branch entered through block, such as "continue Outer" in
+// "if done { continue Outer }". go/cfg turns such a branch into an empty
+// block jumping to the target, so its statement is not otherwise visible.
+func branchVia(block *cfg.Block) *ast.BranchStmt {
+	if len(block.Nodes) > 0 {
+		return nil
+	}
+	var body []ast.Stmt
+	switch stmt := block.Stmt.(type) {
+	case *ast.IfStmt:
+		switch block.Kind {
+		case cfg.KindIfThen:
+			body = stmt.Body.List
+		case cfg.KindIfElse:
+			if elseBlock, ok := stmt.Else.(*ast.BlockStmt); ok {
+				body = elseBlock.List
+			}
+		}
+	case *ast.CaseClause:
+		if block.Kind == cfg.KindSwitchCaseBody {
+			body = stmt.Body
+		}
+	case *ast.CommClause:
+		if block.Kind == cfg.KindSelectCaseBody {
+			body = stmt.Body
+		}
+	}
+	if len(body) != 1 {
+		return nil
+	}
+	branch, ok := body[0].(*ast.BranchStmt)
+	if !ok || branch.Tok == token.FALLTHROUGH {
+		return nil
+	}
+	return branch
+}
+
+// entersDefault reports whether an edge to succ enters the body of a
+// switch's default clause. go/cfg reaches the default through the empty
+// "next case" block left after the last case, which is followed here.
+func entersDefault(succ *cfg.Block) bool {
+	for block := succ; ; block = block.Succs[0] {
+		if clause, ok := block.Stmt.(*ast.CaseClause); ok && block.Kind == cfg.KindSwitchCaseBody {
+			return clause.List == nil
+		}
+		if block.Kind != cfg.KindSwitchNextCase || len(block.Nodes) > 0 || len(block.Succs) != 1 {
+			return false
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+	"testing"
+)
+
+func TestBreakInSwitchTargetsSwitch(t *testing.T) {
+	dot := snippetDot(t, DefaultOptions(), `for i := 0; i < 3; i++ {
+	switch i {
+	case 1:
+		break
+	}
+	println(i)
+}
+println(9)`)
+	for _, edge := range dotEdges(dot) {
+		if !strings.Contains(edge.attrs, `label="break"`) {
+			continue
+		}
+		if edge.to != "println(i)" {
+			t.Errorf("break jumps to %q, want the statement after the switch, println(i)", edge.to)
+		}
+		return
+	}
+	t.Errorf("no break edge in\n%s", dot)
+}
+
+func TestLabeledBreakTargetsLoop(t *testing.T) {
+	dot := snippetDot(t, DefaultOptions(), `Outer:
+for i := 0; i < 3; i++ {
+	switch i {
+	case 1:
+		break Outer
+	}
+	println(i)
+}
+println(9)`)
+	for _, edge := range dotEdges(dot) {
+		if strings.Contains(edge.attrs, `label="break Outer"`) {
+			if edge.to != "println(9)" {
+				t.Errorf("break Outer jumps to %q, want println(9)", edge.to)
+			}
+			return
+		}
+	}
+	t.Errorf("no break Outer edge in\n%s", dot)
+}
+
+func TestBranchTarget(t *testing.T) {
+	tests := []struct {
+		name, body, want string // want is the target's type, "" for none
+	}{
+		{"continue outside loop", "switch {\ncase a > 0:\n\tcontinue\n}", ""},
+		{"break outside loop", "if a > 0 {\n\tbreak\n}", ""},
+		{"break in select", "for {\n\tselect {\n\tdefault:\n\t\tbreak\n\t}\n}", "*ast.SelectStmt"},
+		{"continue in switch", "for range s {\n\tswitch {\n\tdefault:\n\t\tcontinue\n\t}\n}", "*ast.RangeStmt"},
+		{"break in function literal", "for {\n\tf := func() {\n\t\tbreak\n\t}\n\tf()\n}", ""},
+		{"labeled continue", "Outer:\nfor {\n\tswitch {\n\tdefault:\n\t\tcontinue Outer\n\t}\n}", "*ast.ForStmt"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, fn := parseSnippet(t, test.body)
+			got := ""
+			if target := branchTarget(fn, firstOf[*ast.BranchStmt](t, fn)); target != nil {
+				got = fmt.Sprintf("%T", target)
+			}
+			if got != test.want {
+				t.Errorf("target %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBranchViaEdges(t *testing.T) {
+	const loops = "Outer:\nfor i := 0; i < n; i++ {\n\tfor j := 0; j < m; j++ {\n\t\tif a > b {\n\t\t\t%s\n\t\t}\n\t\tx()\n\t}\n\ty()\n}\nz()"
+	tests := []struct {
+		name, body, from, label, to string
+	}{
+		{"continue outer", fmt.Sprintf(loops, "continue Outer"), "a > b", "continue Outer", "i ++"},
+		{"break outer", fmt.Sprintf(loops, "break Outer"), "a > b", "break Outer", "z()"},
+		{"continue", fmt.Sprintf(loops, "continue"), "a > b", "continue", "j ++"},
+		{"break", fmt.Sprintf(loops, "break"), "a > b", "break", "y()"},
+		{"goto", "if a > b {\n\tgoto End\n}\nx()\nEnd:\n\tz()", "a > b", "goto End", "z()"},
+		{"else branch", "for {\n\tif a > b {\n\t\tx()\n\t} else {\n\t\tbreak\n\t}\n}\nz()", "a > b", "break", "z()"},
+		{"case body", "for {\n\tswitch {\n\tcase a > b:\n\t\tx()\n\tcase c > d:\n\t\tcontinue\n\t}\n\ty()\n}", "c > d", "continue", "a > b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dot := snippetDot(t, DefaultOptions(), test.body)
+			var labeled []dotEdge
+			for _, edge := range edgesFrom(dot, test.from) {
+				if strings.Contains(edge.attrs, `label="`+test.label+`"`) {
+					labeled = append(labeled, edge)
+				}
+			}
+			if len(labeled) != 1 || labeled[0].to != test.to {
+				t.Errorf("edges from %q labeled %q: %v, want one to %q:\n%s", test.from, test.label, labeled, test.to, dot)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// CallCategories lists the call target categories, in the order they are
+// reported.
+var CallCategories = []string{"builtin", "stdlib", "package", "external", "local/indirect", "unknown"}
+
+// classifyCalls counts the calls made by fn per target category: builtins,
+// the standard library, the function's own package, external packages, and
+// function values held in variables, such as parameters and closures
+// ("local/indirect"). Calls whose target cannot be determined (method calls
+// without type information, calls of expressions) are counted as "unknown".
+// Type conversions are not calls; they are counted separately in
+// conversions.
+func classifyCalls(file *ast.File, fn *ast.FuncDecl, info *types.Info) (counts map[string]int, conversions int) {
+	imports := importPaths(file)
+	localTypes := declaredTypes(file, fn)
+	locals := localVars(fn)
+	var pkg *types.Package
+	if info != nil {
+		if obj := info.Defs[fn.Name]; obj != nil {
+			pkg = obj.Pkg()
+		}
+	}
+
+	counts = make(map[string]int)
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if isConversion(call, info, localTypes) {
+			conversions++
+			return true
+		}
+		if info != nil {
+			if category, ok := classifyTypedCall(call, info, pkg); ok {
+				if category != "" {
+					counts[category]++
+				}
+				return true
+			}
+		}
+		counts[classifyCallByName(call, imports, locals)]++
+		return true
+	})
+	return counts, conversions
+}
+
+// isConversion reports whether call is a type conversion such as int(x),
+// []byte(s) or MyType(v). With type information this is exact; without it
+// the target must be a type literal, a predeclared type or a type declared
+// in localTypes. Conversions to types of other packages, e.g.
+// time.Duration(n), cannot be told from calls by syntax alone.
+func isConversion(call *ast.CallExpr, info *types.Info, localTypes map[string]bool) bool {
+	if info != nil {
+		if tv, ok := info.Types[call.Fun]; ok {
+			return tv.IsType()
+		}
+	}
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StructType, *ast.StarExpr:
+		return true
+	case *ast.Ident:
+		if _, ok := types.Universe.Lookup(fun.Name).(*types.TypeName); ok {
+			return true
+		}
+		return localTypes[fun.Name]
+	}
+	return false
+}
+
+// declaredTypes returns the names of the types declared in file and the type
+// parameters of fn.
+func declaredTypes(file *ast.File, fn *ast.FuncDecl) map[string]bool {
+	names := make(map[string]bool)
+	if file != nil {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				names[spec.Name.Name] = true
+			}
+			return true
+		})
+	}
+	if fn.Type.TypeParams != nil {
+		for _, field := range fn.Type.TypeParams.List {
+			for _, name := range field.Names {
+				names[name.Name] = true
+			}
+		}
+	}
+	return names
+}
+
+// localVars returns the names of the parameters, results and receiver of fn
+// and of the variables declared in its body, including those of function
+// literals. Shadowing is ignored: a name declared anywhere in fn counts as a
+// variable throughout.
+func localVars(fn *ast.FuncDecl) map[string]bool {
+	names := make(map[string]bool)
+	addFields := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				names[name.Name] = true
+			}
+		}
+	}
+	addFields(fn.Recv)
+	addFields(fn.Type.Params)
+	addFields(fn.Type.Results)
+	addIdents := func(exprs ...ast.Expr) {
+		for _, expr := range exprs {
+			if ident, ok := expr.(*ast.Ident); ok {
+				names[ident.Name] = true
+			}
+		}
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				addIdents(n.Lhs...)
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				addIdents(n.Key, n.Value)
+			}
+		case *ast.GenDecl:
+			// Constants are not variables.
+			return n.Tok != token.CONST
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				names[name.Name] = true
+			}
+		case *ast.FuncLit:
+			addFields(n.Type.Params)
+			addFields(n.Type.Results)
+		}
+		return true
+	})
+	delete(names, "_")
+	return names
+}
+
+// classifyTypedCall resolves the call target with type information. It
+// reports false when the target is unknown to the type checker and returns an
+// empty category for conversions.
+func classifyTypedCall(call *ast.CallExpr, info *types.Info, pkg *types.Package) (string, bool) {
+	if tv, ok := info.Types[call.Fun]; ok && tv.IsType() {
+		return "", true
+	}
+	if ident, ok := ast.Unparen(call.Fun).(*ast.Ident); ok {
+		if _, ok := info.Uses[ident].(*types.Var); ok {
+			return "local/indirect", true
+		}
+	}
+	obj := typeutil.Callee(info, call)
+	if obj == nil {
+		return "", false
+	}
+	switch {
+	case obj.Pkg() == nil:
+		return "builtin", true
+	case pkg != nil && obj.Pkg() == pkg:
+		return "package", true
+	case isStdlibPath(obj.Pkg().Path()):
+		return "stdlib", true
+	default:
+		return "external", true
+	}
+}
+
+// classifyCallByName classifies a call syntactically: plain identifiers are
+// the variables in locals, builtins or package-level functions, and
+// selectors on an imported package name take the category of the import
+// path.
+func classifyCallByName(call *ast.CallExpr, imports map[string]string, locals map[string]bool) string {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		if locals[fun.Name] {
+			return "local/indirect"
+		}
+		if _, ok := types.Universe.Lookup(fun.Name).(*types.Builtin); ok {
+			return "builtin"
+		}
+		return "package"
+	case *ast.SelectorExpr:
+		if ident, ok := fun.X.(*ast.Ident); ok {
+			if path, ok := imports[ident.Name]; ok {
+				if isStdlibPath(path) {
+					return "stdlib"
+				}
+				return "external"
+			}
+		}
+	}
+	return "unknown"
+}
+
+// importPaths maps the names under which file refers to its imports to the
+// imported paths.
+func importPaths(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	if file == nil {
+		return imports
+	}
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+// stdlibPaths caches the answers of isStdlibPath by import path.
+var stdlibPaths = make(map[string]bool)
+
+// isStdlibPath reports whether path is a standard library import path, i.e.
+// a package directory under $GOROOT/src. A module path without a dot, such
+// as mycorp/util, is not. When GOROOT is unknown it falls back to checking
+// that the first path element contains no dot.
+func isStdlibPath(path string) bool {
+	if std, ok := stdlibPaths[path]; ok {
+		return std
+	}
+	var std bool
+	if goroot := build.Default.GOROOT; goroot != "" {
+		info, err := os.Stat(filepath.Join(goroot, "src", filepath.FromSlash(path)))
+		std = err == nil && info.IsDir() && path != "cmd" && !strings.HasPrefix(path, "cmd/")
+	} else {
+		first, _, _ := strings.Cut(path, "/")
+		std = !strings.Contains(first, ".")
+	}
+	stdlibPaths[path] = std
+	return std
+}
+
+// calleeNames counts the calls made by fn by the name of their target: the
+// function name of plain calls and the method name of selector calls.
+// Conversions and calls into imported packages are left out, so the names
+// can be matched against the functions analyzed in the same run.
+func calleeNames(file *ast.File, fn *ast.FuncDecl) map[string]int {
+	imports := importPaths(file)
+	localTypes := declaredTypes(file, fn)
+	names := make(map[string]int)
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || isConversion(call, nil, localTypes) {
+			return true
+		}
+		switch fun := ast.Unparen(call.Fun).(type) {
+		case *ast.Ident:
+			names[fun.Name]++
+		case *ast.SelectorExpr:
+			if ident, ok := fun.X.(*ast.Ident); ok && imports[ident.Name] != "" {
+				break
+			}
+			names[fun.Sel.Name]++
+		}
+		return true
+	})
+	return names
+}
+
+// CallGraphDot renders the calls between the analyzed functions as a DOT
+// graph with one node per function and edges labeled with call counts. A
+// call of a method name is drawn to every analyzed method of that name, since
+// calleeNames does not know the receiver type.
+func CallGraphDot(results []*Result) string {
+	analyzed := make(map[string][]string) // by unqualified name
+	var sb strings.Builder
+	sb.WriteString("digraph CallGraph {\n  node [shape=box];\n")
+	for _, result := range results {
+		name := result.Metrics.Name
+		short := name[strings.LastIndex(name, ".")+1:]
+		if !slices.Contains(analyzed[short], name) {
+			analyzed[short] = append(analyzed[short], name)
+			fmt.Fprintf(&sb, "  \"%s\";\n", escapeLabel(name))
+		}
+	}
+	for _, result := range results {
+		for _, callee := range sortedKeys(result.Callees) {
+			for _, target := range analyzed[callee] {
+				fmt.Fprintf(&sb, "  \"%s\" -> \"%s\" [label=\"%d\"];\n", escapeLabel(result.Metrics.Name), escapeLabel(target), result.Callees[callee])
+			}
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// printCallCategories prints the call target breakdown of a function and
+// the number of type conversions, which are not counted as calls.
+func printCallCategories(w io.Writer, counts map[string]int, conversions int) {
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintln(w, "Calls by target:")
+	for _, category := range CallCategories {
+		fmt.Fprintf(w, "  %s: %d\n", category, counts[category])
+	}
+	fmt.Fprintf(w, "Conversions: %d\n", conversions)
+}
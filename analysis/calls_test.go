@@ -0,0 +1,150 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/types"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestClassifyCalls(t *testing.T) {
+	_, file, fn := parseSource(t, `package p
+
+import (
+	"strings"
+	"time"
+
+	"example.com/lib"
+	"mycorp/util"
+)
+
+type Celsius float64
+
+func f[T any](x int, s string, v any, cb func()) {
+	_ = int(x)
+	_ = []byte(s)
+	_ = Celsius(1.5)
+	_ = T(v)
+	_ = (*int)(nil)
+	_ = time.Duration(x)
+	_ = len(s)
+	_ = strings.ToUpper(s)
+	lib.Do()
+	util.Do()
+	g()
+	cb()
+	h := func() {}
+	h()
+	for _, k := range []func(){h} {
+		k()
+	}
+	s.m()
+	func() {}()
+}
+`)
+	counts, conversions := classifyCalls(file, fn, nil)
+	if conversions != 5 {
+		t.Errorf("%d conversions, want 5", conversions)
+	}
+	// time.Duration(x) cannot be told from a call without type information.
+	want := map[string]int{"builtin": 1, "stdlib": 2, "external": 2, "package": 1, "local/indirect": 3, "unknown": 2}
+	if !maps.Equal(counts, want) {
+		t.Errorf("calls %v, want %v", counts, want)
+	}
+}
+
+func TestClassifyTypedCalls(t *testing.T) {
+	fset, file, fn := parseSource(t, `package p
+
+var handler = func() {}
+
+func f(cb func()) {
+	cb()
+	handler()
+	g := func() {}
+	g()
+	h()
+	_ = len("")
+}
+
+func h() {}
+`)
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	if _, err := new(types.Config).Check("p", fset, []*ast.File{file}, info); err != nil {
+		t.Fatal(err)
+	}
+	counts, _ := classifyCalls(file, fn, info)
+	want := map[string]int{"local/indirect": 3, "package": 1, "builtin": 1}
+	if !maps.Equal(counts, want) {
+		t.Errorf("calls %v, want %v", counts, want)
+	}
+}
+
+func TestIsStdlibPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"fmt", true},
+		{"net/http", true},
+		{"golang.org/x/tools/go/cfg", false},
+		{"mycorp/util", false},
+		{"util", false},
+		{"cmd/go", false},
+	}
+	for _, test := range tests {
+		if got := isStdlibPath(test.path); got != test.want {
+			t.Errorf("isStdlibPath(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestCallGraphDot(t *testing.T) {
+	_, file, _ := parseSource(t, `package p
+
+import "strings"
+
+type A struct{}
+type B struct{}
+
+func (A) String() string { return "a" }
+func (*B) String() string { return strings.ToUpper("b") }
+
+func main() {
+	helper()
+	helper()
+	var a A
+	_ = a.String()
+	_ = int(3)
+}
+
+func helper() {
+	println(A{}.String())
+}
+`)
+	var results []*Result
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			results = append(results, &Result{Metrics: Metrics{Name: funcName(fn)}, Callees: calleeNames(file, fn)})
+		}
+	}
+	dot := CallGraphDot(results)
+	for _, want := range []string{
+		`"main" -> "helper" [label="2"];`,
+		`"main" -> "A.String" [label="1"];`,
+		`"main" -> "(*B).String" [label="1"];`,
+		`"helper" -> "A.String" [label="1"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("no %s in\n%s", want, dot)
+		}
+	}
+	if edges := strings.Count(dot, "->"); edges != 5 {
+		t.Errorf("%d edges, want 5:\n%s", edges, dot)
+	}
+}
@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ClusterDot joins the DOT graphs of results into a single digraph with one
+// cluster subgraph per function, labeled with its name. The node IDs of the
+// i-th function get the prefix f<i>_ so the functions stay apart; labels
+// and other attributes are left as they are.
+func ClusterDot(results []*Result) string {
+	var sb strings.Builder
+	sb.WriteString("digraph G {\n")
+	for i, result := range results {
+		fmt.Fprintf(&sb, "  subgraph cluster_%d {\n    label=\"%s\";\n", i, escapeLabel(result.Metrics.Name))
+		prefix := fmt.Sprintf("f%d_", i)
+		lines := strings.Split(strings.TrimSpace(result.Dot), "\n")
+		// The first and last lines open and close the function's digraph.
+		for _, line := range lines[1 : len(lines)-1] {
+			ids, attrs, _ := strings.Cut(line, " [")
+			ids = dotLineIDs.ReplaceAllString(ids, prefix+"$0")
+			if attrs != "" {
+				attrs = " [" + attrs
+			}
+			sb.WriteString("  " + ids + attrs + "\n")
+		}
+		sb.WriteString("  }\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
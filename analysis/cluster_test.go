@@ -0,0 +1,35 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClusterDot(t *testing.T) {
+	var results []*Result
+	for _, body := range []string{"block_1 := 1\nx(block_1)", "if a > 0 {\n\ty()\n}"} {
+		dot := snippetDot(t, DefaultOptions(), body)
+		results = append(results, &Result{Metrics: Metrics{Name: "_"}, Dot: dot})
+	}
+	results[1].Metrics.Name = `"q"`
+	got := ClusterDot(results)
+	if n := strings.Count(got, "digraph"); n != 1 {
+		t.Errorf("%d digraphs, want 1:\n%s", n, got)
+	}
+	for _, want := range []string{
+		"digraph G {\n  subgraph cluster_0 {\n    label=\"_\";\n",
+		"  subgraph cluster_1 {\n    label=\"\\\"q\\\"\";\n",
+		`    f0_block_0_node_0 [label="block_1 = 1"];`,
+		"    f0_block_0_node_0 -> f0_block_0_node_1",
+		`    f1_block_0_node_0 [label="a > 0"];`,
+		"    f1_block_0_node_0 -> f1_block_1_node_0 [",
+		"  }\n}\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("no %q in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "\n    block_") {
+		t.Errorf("node IDs without a function prefix:\n%s", got)
+	}
+}
@@ -0,0 +1,183 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// cognitiveComplexity computes the cognitive complexity of fn following the
+// SonarSource definition:
+//
+//   - if, for, range, switch, type switch and select add 1 plus the current
+//     nesting level, and increase the nesting level of their bodies;
+//   - else if and else add 1 without a nesting penalty;
+//   - every sequence of like boolean operators (&& or ||) adds 1;
+//   - break, continue and goto to a label add 1;
+//   - a direct recursive call adds 1;
+//   - function literals increase the nesting level of their bodies.
+//
+// Individual case clauses of a switch or select add nothing.
+func cognitiveComplexity(fn *ast.FuncDecl) int {
+	c := &cognitive{name: fn.Name.Name}
+	c.stmts(fn.Body.List, 0)
+	return c.total
+}
+
+type cognitive struct {
+	name  string
+	total int
+}
+
+func (c *cognitive) stmts(list []ast.Stmt, nesting int) {
+	for _, stmt := range list {
+		c.stmt(stmt, nesting)
+	}
+}
+
+func (c *cognitive) stmt(stmt ast.Stmt, nesting int) {
+	switch s := stmt.(type) {
+	case *ast.IfStmt:
+		c.total += 1 + nesting
+		c.ifChain(s, nesting)
+	case *ast.ForStmt:
+		c.total += 1 + nesting
+		c.optStmt(s.Init, nesting)
+		c.expr(s.Cond, nesting)
+		c.optStmt(s.Post, nesting)
+		c.stmts(s.Body.List, nesting+1)
+	case *ast.RangeStmt:
+		c.total += 1 + nesting
+		c.expr(s.X, nesting)
+		c.stmts(s.Body.List, nesting+1)
+	case *ast.SwitchStmt:
+		c.total += 1 + nesting
+		c.optStmt(s.Init, nesting)
+		c.expr(s.Tag, nesting)
+		c.clauses(s.Body, nesting+1)
+	case *ast.TypeSwitchStmt:
+		c.total += 1 + nesting
+		c.optStmt(s.Init, nesting)
+		c.optStmt(s.Assign, nesting)
+		c.clauses(s.Body, nesting+1)
+	case *ast.SelectStmt:
+		c.total += 1 + nesting
+		c.clauses(s.Body, nesting+1)
+	case *ast.BranchStmt:
+		if s.Label != nil || s.Tok == token.GOTO {
+			c.total++
+		}
+	case *ast.LabeledStmt:
+		c.stmt(s.Stmt, nesting)
+	case *ast.BlockStmt:
+		c.stmts(s.List, nesting)
+	default:
+		c.node(stmt, nesting)
+	}
+}
+
+// ifChain scores the condition and branches of s; the increment for s itself
+// has already been added.
+func (c *cognitive) ifChain(s *ast.IfStmt, nesting int) {
+	c.optStmt(s.Init, nesting)
+	c.expr(s.Cond, nesting)
+	c.stmts(s.Body.List, nesting+1)
+	switch e := s.Else.(type) {
+	case *ast.IfStmt:
+		c.total++ // else if
+		c.ifChain(e, nesting)
+	case *ast.BlockStmt:
+		c.total++ // else
+		c.stmts(e.List, nesting+1)
+	}
+}
+
+func (c *cognitive) clauses(body *ast.BlockStmt, nesting int) {
+	for _, clause := range body.List {
+		switch cl := clause.(type) {
+		case *ast.CaseClause:
+			for _, expr := range cl.List {
+				c.expr(expr, nesting-1)
+			}
+			c.stmts(cl.Body, nesting)
+		case *ast.CommClause:
+			c.optStmt(cl.Comm, nesting-1)
+			c.stmts(cl.Body, nesting)
+		}
+	}
+}
+
+func (c *cognitive) optStmt(stmt ast.Stmt, nesting int) {
+	if stmt != nil {
+		c.stmt(stmt, nesting)
+	}
+}
+
+func (c *cognitive) expr(expr ast.Expr, nesting int) {
+	if expr != nil {
+		c.node(expr, nesting)
+	}
+}
+
+// node scores the expressions inside a simple statement or expression:
+// boolean operator sequences, recursive calls and function literals.
+func (c *cognitive) node(node ast.Node, nesting int) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.FuncLit:
+			c.stmts(e.Body.List, nesting+1)
+			return false
+		case *ast.CallExpr:
+			if ident, ok := e.Fun.(*ast.Ident); ok && ident.Name == c.name {
+				c.total++
+			}
+		case *ast.BinaryExpr:
+			if e.Op == token.LAND || e.Op == token.LOR {
+				ops := logicalOps(e, nil)
+				c.total++
+				for i := 1; i < len(ops); i++ {
+					if ops[i] != ops[i-1] {
+						c.total++
+					}
+				}
+				// Operands that are not part of this sequence may hold
+				// their own calls, literals and sequences.
+				for _, operand := range logicalOperands(e, nil) {
+					c.node(operand, nesting)
+				}
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// logicalOps appends the && and || operators of the boolean expression tree
+// rooted at expr, in source order, looking through parentheses.
+func logicalOps(expr ast.Expr, ops []token.Token) []token.Token {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return logicalOps(e.X, ops)
+	case *ast.BinaryExpr:
+		if e.Op == token.LAND || e.Op == token.LOR {
+			ops = logicalOps(e.X, ops)
+			ops = append(ops, e.Op)
+			return logicalOps(e.Y, ops)
+		}
+	}
+	return ops
+}
+
+// logicalOperands appends the operands joined by the boolean expression tree
+// rooted at expr.
+func logicalOperands(expr ast.Expr, operands []ast.Expr) []ast.Expr {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return logicalOperands(e.X, operands)
+	case *ast.BinaryExpr:
+		if e.Op == token.LAND || e.Op == token.LOR {
+			operands = logicalOperands(e.X, operands)
+			return logicalOperands(e.Y, operands)
+		}
+	}
+	return append(operands, expr)
+}
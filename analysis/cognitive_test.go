@@ -0,0 +1,29 @@
+package analysis
+
+import "testing"
+
+func TestCognitiveComplexity(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"straight line", "x()", 0},
+		{"if else if else", "if a > 0 {\n} else if a < 0 {\n} else {\n}", 3},
+		{"nested if", "for i < n {\n\tif i > 2 {\n\t}\n}", 3},
+		{"switch", "switch a {\ncase 1:\ncase 2:\ndefault:\n}", 1},
+		{"switch in loop", "for {\n\tswitch a {\n\tcase 1:\n\t\tif b > 0 {\n\t\t}\n\t}\n}", 6},
+		{"type switch", "switch v := x.(type) {\ncase int:\n\t_ = v\n}", 1},
+		{"select in loop", "for {\n\tselect {\n\tcase <-ch:\n\tdefault:\n\t}\n}", 3},
+		{"boolean sequences", "if a > 0 && b > 0 || c > 0 {\n}", 3},
+		{"labeled continue", "Outer:\n\tfor {\n\t\tfor {\n\t\t\tcontinue Outer\n\t\t}\n\t}", 4},
+		{"function literal", "f := func() {\n\tif a > 0 {\n\t}\n}\nf()", 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, fn := parseSnippet(t, test.body)
+			if got := cognitiveComplexity(fn); got != test.want {
+				t.Errorf("cognitive complexity %d, want %d", got, test.want)
+			}
+		})
+	}
+}
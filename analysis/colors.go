@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// ColorSchemes lists the values of Options.ColorScheme.
+var ColorSchemes = []string{"default", "grayscale", "high-contrast", "colorblind-safe"}
+
+// colorScheme holds the colors the DOT graphs are drawn with. An empty fill
+// leaves nodes unfilled.
+type colorScheme struct {
+	then      string // edges into a then branch or a loop body
+	otherwise string // edges into an else branch or out of a loop
+	edge      string // all other control flow edges
+	recursion string // recursive call nodes and their edges
+	fill      string
+
+	// Label text colors used by -html-labels.
+	keyword    string
+	identifier string
+	literal    string
+}
+
+// colorSchemes maps the -color-scheme presets to their colors. All but the
+// default use RGB values only, so the output does not depend on the color
+// names a renderer knows; colorblind-safe uses the Okabe-Ito palette.
+var colorSchemes = map[string]colorScheme{
+	"default": {then: "yellow", otherwise: "red", edge: "black", recursion: "purple",
+		keyword: "blue", identifier: "darkgreen", literal: "darkorange"},
+	"grayscale": {then: "#a0a0a0", otherwise: "#404040", edge: "#000000", recursion: "#707070", fill: "#f0f0f0",
+		keyword: "#000000", identifier: "#505050", literal: "#808080"},
+	"high-contrast": {then: "#0000ff", otherwise: "#ff0000", edge: "#000000", recursion: "#ff00ff", fill: "#ffffff",
+		keyword: "#0000ff", identifier: "#000000", literal: "#008000"},
+	"colorblind-safe": {then: "#0072b2", otherwise: "#e69f00", edge: "#000000", recursion: "#cc79a7", fill: "#f0e442",
+		keyword: "#0072b2", identifier: "#000000", literal: "#d55e00"},
+}
+
+// colors returns the scheme selected by the options, the default one if
+// there is none.
+func (a *analyzer) colors() colorScheme {
+	if scheme, ok := colorSchemes[a.opts.ColorScheme]; ok {
+		return scheme
+	}
+	return colorSchemes["default"]
+}
+
+// branchColor returns the color of an edge into a block of the given kind.
+func (c colorScheme) branchColor(kind cfg.BlockKind) string {
+	switch kind {
+	case cfg.KindIfThen, cfg.KindForBody, cfg.KindRangeBody:
+		return c.then
+	case cfg.KindIfDone, cfg.KindIfElse, cfg.KindForDone, cfg.KindRangeDone:
+		return c.otherwise
+	}
+	return c.edge
+}
+
+// nodeDefaults returns the DOT default node attribute statement for the
+// scheme's fill, or "" if nodes are unfilled.
+func (c colorScheme) nodeDefaults() string {
+	if c.fill == "" {
+		return ""
+	}
+	return fmt.Sprintf("  node [style=filled fillcolor=\"%s\"];\n", c.fill)
+}
+
+// recursionStyle returns the style of recursive call nodes. A node's style
+// replaces the default one rather than adding to it, so it repeats filled
+// when the scheme fills nodes.
+func (c colorScheme) recursionStyle() string {
+	if c.fill == "" {
+		return "dashed"
+	}
+	return "filled,dashed"
+}
@@ -0,0 +1,38 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColorSchemes(t *testing.T) {
+	tests := []struct {
+		scheme, then, otherwise, fill string
+	}{
+		{"default", "yellow", "red", ""},
+		{"grayscale", "#a0a0a0", "#404040", "#f0f0f0"},
+		{"high-contrast", "#0000ff", "#ff0000", "#ffffff"},
+		{"colorblind-safe", "#0072b2", "#e69f00", "#f0e442"},
+	}
+	for _, test := range tests {
+		t.Run(test.scheme, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.ColorScheme = test.scheme
+			dot := snippetDot(t, opts, "if a > b {\n\tx()\n} else {\n\tz()\n}")
+			want := map[string]string{"x()": test.then, "z()": test.otherwise}
+			edges := edgesFrom(dot, "a > b")
+			if len(edges) != 2 {
+				t.Fatalf("%d edges leave the condition, want 2:\n%s", len(edges), dot)
+			}
+			for _, edge := range edges {
+				if color := `color="` + want[edge.to] + `"`; !strings.Contains(edge.attrs, color) {
+					t.Errorf("edge to %q has [%s], want %s", edge.to, edge.attrs, color)
+				}
+			}
+			filled := strings.Contains(dot, `node [style=filled fillcolor="`+test.fill+`"]`)
+			if filled != (test.fill != "") {
+				t.Errorf("fill %q: node defaults filled = %v:\n%s", test.fill, filled, dot)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// pureBuiltins are the builtins that only compute a value; calling them in a
+// condition is not reported.
+var pureBuiltins = map[string]bool{
+	"len": true, "cap": true, "min": true, "max": true,
+	"complex": true, "real": true, "imag": true,
+}
+
+// conditionCalls reports the calls in the conditions of the if, for and
+// switch statements of fn: a condition that may have side effects is harder
+// to reason about than one that only reads values. Conversions and the pure
+// builtins are not calls in this sense, and neither are the calls inside a
+// function literal of the condition, which only runs if called.
+func conditionCalls(fset *token.FileSet, file *ast.File, fn *ast.FuncDecl) []Finding {
+	var findings []Finding
+	localTypes := declaredTypes(file, fn)
+	check := func(keyword string, cond ast.Expr) {
+		if cond == nil {
+			return
+		}
+		ast.Inspect(cond, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.CallExpr:
+				if isConversion(n, nil, localTypes) {
+					return true
+				}
+				if ident, ok := ast.Unparen(n.Fun).(*ast.Ident); ok && pureBuiltins[ident.Name] {
+					return true
+				}
+				findings = append(findings, newFinding(fset, n, "condition-call", "note",
+					fmt.Sprintf("%s condition calls %s, which may have side effects", keyword, getValue(n.Fun))))
+			}
+			return true
+		})
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			check("if", n.Cond)
+		case *ast.ForStmt:
+			check("for", n.Cond)
+		case *ast.SwitchStmt:
+			check("switch", n.Tag)
+		}
+		return true
+	})
+	return findings
+}
@@ -0,0 +1,390 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// defUse is a data-flow edge of -def-use: the definition of name at node def
+// reaches its use at node use.
+type defUse struct {
+	def, use, name string
+}
+
+// reachingUse is a use of a variable together with the IDs of the nodes
+// whose definitions of it may reach the use. The ID "" stands for the
+// variable being still undefined, see reachingDefs.
+type reachingUse struct {
+	nodeID, name string
+	node         ast.Node
+	defs         map[string]bool
+}
+
+// definition is a node defining a variable.
+type definition struct {
+	nodeID, name string
+	node         ast.Node
+}
+
+// reachingDefs computes the reaching definitions of the live blocks of cg
+// and returns every definition and every use, with the definitions reaching
+// it, in block and node order. Where control flow merges, as after an if
+// whose arms both assign a variable, a use is reached by the definitions of
+// every incoming path. The variables in undefined start out undefined at
+// the entry; the others, such as parameters, are defined outside the CFG and
+// have no definition node.
+func reachingDefs(cg *cfg.CFG, v *varNamer, info *types.Info, undefined []string) ([]definition, []reachingUse) {
+	// reaching maps a variable to the IDs of the nodes whose definition of
+	// it may reach the current point.
+	type reaching map[string]map[string]bool
+	copyOf := func(r reaching) reaching {
+		c := make(reaching, len(r))
+		for name, defs := range r {
+			c[name] = make(map[string]bool, len(defs))
+			for def := range defs {
+				c[name][def] = true
+			}
+		}
+		return c
+	}
+
+	// transfer applies the nodes of block to in, calling use for every use
+	// of a variable with the definitions reaching it and def for every
+	// definition.
+	transfer := func(block *cfg.Block, in reaching, use func(reachingUse), def func(definition)) reaching {
+		out := copyOf(in)
+		for i, node := range block.Nodes {
+			nodeID := fmt.Sprintf("block_%d_node_%d", block.Index, i)
+			defs, uses := defsUses(asRangeHeader(block, node), v, info)
+			if use != nil {
+				for _, name := range uses {
+					use(reachingUse{nodeID, name, node, out[name]})
+				}
+			}
+			for _, name := range defs {
+				out[name] = map[string]bool{nodeID: true}
+				if def != nil {
+					def(definition{nodeID, name, node})
+				}
+			}
+		}
+		return out
+	}
+
+	entry := make(reaching)
+	for _, name := range undefined {
+		entry[name] = map[string]bool{"": true}
+	}
+	ins := make(map[int32]reaching)
+	outs := make(map[int32]reaching)
+	size := func() int {
+		n := 0
+		for _, out := range outs {
+			for _, defs := range out {
+				n += len(defs)
+			}
+		}
+		return n
+	}
+	for changed := true; changed; {
+		before := size()
+		for _, block := range executionOrder(cg) {
+			in := make(reaching)
+			if block.Index == 0 {
+				in = copyOf(entry)
+			}
+			for _, pred := range cg.Blocks {
+				if !pred.Live || !slices.Contains(pred.Succs, block) {
+					continue
+				}
+				for name, defs := range outs[pred.Index] {
+					if in[name] == nil {
+						in[name] = make(map[string]bool)
+					}
+					for def := range defs {
+						in[name][def] = true
+					}
+				}
+			}
+			ins[block.Index] = in
+			outs[block.Index] = transfer(block, in, nil, nil)
+		}
+		// The sets only grow, so an unchanged total means a fixed point.
+		changed = size() != before
+	}
+
+	var defs []definition
+	var uses []reachingUse
+	for _, block := range cg.Blocks {
+		if !block.Live {
+			continue
+		}
+		transfer(block, ins[block.Index], func(u reachingUse) {
+			uses = append(uses, u)
+		}, func(d definition) {
+			defs = append(defs, d)
+		})
+	}
+	return defs, uses
+}
+
+// defUseEdges returns an edge from every definition of a variable in cg to
+// every use it reaches, in block and node order; see reachingDefs.
+func defUseEdges(cg *cfg.CFG, v *varNamer, info *types.Info) []defUse {
+	_, uses := reachingDefs(cg, v, info, nil)
+	var edges []defUse
+	for _, use := range uses {
+		for _, def := range sortedKeys(use.defs) {
+			edges = append(edges, defUse{def: def, use: use.nodeID, name: use.name})
+		}
+	}
+	return edges
+}
+
+// dataFlowFindings reports the dead stores of fn, definitions of a local
+// variable whose value no use reads, and its uses of a local variable that
+// some path reaches before any definition. Variables are told apart by
+// name, and only those declared in fn are checked. The dead store check
+// leaves out named results, which a return reads implicitly, variables
+// that function literals refer to, that are called or whose address is
+// taken, since they may be read in ways the data flow does not see, and
+// declarations without a value, whose zero value is rarely meant to be
+// read.
+func dataFlowFindings(fset *token.FileSet, fn *ast.FuncDecl, cg *cfg.CFG) []Finding {
+	v := newVarNamer(nil)
+	// The receiver, parameters and results are defined at the entry.
+	params := make(map[string]bool)
+	for _, fields := range []*ast.FieldList{fn.Recv, fn.Type.Params, fn.Type.Results} {
+		if fields != nil {
+			for _, field := range fields.List {
+				for _, name := range field.Names {
+					params[name.Name] = true
+				}
+			}
+		}
+	}
+	unchecked := make(map[string]bool)
+	if fn.Type.Results != nil {
+		for _, field := range fn.Type.Results.List {
+			for _, name := range field.Names {
+				unchecked[name.Name] = true
+			}
+		}
+	}
+	localTypes := make(map[string]bool)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.TypeSpec:
+			localTypes[n.Name.Name] = true
+		case *ast.FuncLit:
+			ast.Inspect(n.Body, func(n ast.Node) bool {
+				if ident, ok := n.(*ast.Ident); ok {
+					unchecked[ident.Name] = true
+				}
+				return true
+			})
+			return false
+		case *ast.UnaryExpr:
+			if ident, ok := ast.Unparen(n.X).(*ast.Ident); ok && n.Op == token.AND {
+				unchecked[ident.Name] = true
+			}
+		case *ast.CallExpr:
+			// condVars does not count a called function value as a use.
+			if ident, ok := ast.Unparen(n.Fun).(*ast.Ident); ok {
+				unchecked[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	// The variables declared in the body are undefined at the entry; those
+	// assigned without a declaration belong to the package. A name that is
+	// also a local type is left alone, as its uses may be the type's.
+	locals := localVars(fn)
+	for name := range localTypes {
+		delete(locals, name)
+	}
+	var undefined []string
+	for _, name := range sortedKeys(locals) {
+		if !params[name] {
+			undefined = append(undefined, name)
+		}
+	}
+	defs, uses := reachingDefs(cg, v, nil, undefined)
+
+	// A variable's scope starts after its declaration, so a use in or
+	// before the first declaring statement is one of an outer variable.
+	firstDef := make(map[string]token.Pos)
+	for _, def := range defs {
+		if pos, ok := firstDef[def.name]; !ok || def.node.End() < pos {
+			firstDef[def.name] = def.node.End()
+		}
+	}
+
+	var findings []Finding
+	read := make(map[string]bool) // by node ID and variable
+	for _, use := range uses {
+		for def := range use.defs {
+			read[def+" "+use.name] = true
+		}
+		if use.defs[""] && locals[use.name] && use.node.Pos() >= firstDef[use.name] {
+			findings = append(findings, newFinding(fset, identNamed(use.node, use.name), "use-before-def", "warning",
+				fmt.Sprintf("%s may be used before it is defined", use.name)))
+		}
+	}
+	for _, def := range defs {
+		if read[def.nodeID+" "+def.name] || !locals[def.name] || unchecked[def.name] || isZeroDecl(def.node, def.name) {
+			continue
+		}
+		findings = append(findings, newFinding(fset, identNamed(def.node, def.name), "dead-store", "warning",
+			fmt.Sprintf("the value assigned to %s is never used", def.name)))
+	}
+	return findings
+}
+
+// identNamed returns the first identifier called name in node, or node
+// itself if there is none.
+func identNamed(node ast.Node, name string) ast.Node {
+	found := node
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name && found == node {
+			found = ident
+		}
+		return found == node
+	})
+	return found
+}
+
+// isZeroDecl reports whether node declares the variable name without a
+// value, as in "var x int".
+func isZeroDecl(node ast.Node, name string) bool {
+	var specs []ast.Spec
+	switch n := node.(type) {
+	case *ast.ValueSpec:
+		specs = []ast.Spec{n}
+	case *ast.DeclStmt:
+		specs = n.Decl.(*ast.GenDecl).Specs
+	}
+	for _, spec := range specs {
+		if spec, ok := spec.(*ast.ValueSpec); ok && len(spec.Values) == 0 {
+			for _, ident := range spec.Names {
+				if ident.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// defsUses returns the variables node defines and those it uses. A variable
+// both used and redefined, as in x += 1, is in both lists.
+func defsUses(node ast.Node, v *varNamer, info *types.Info) (defs, uses []string) {
+	define := func(idents ...*ast.Ident) {
+		for _, ident := range idents {
+			if ident.Name != "_" {
+				defs = append(defs, v.name(ident))
+			}
+		}
+	}
+	use := func(exprs ...ast.Expr) {
+		for _, expr := range exprs {
+			uses = append(uses, condVars(v, expr)...)
+		}
+	}
+	switch n := node.(type) {
+	case *rangeHeader:
+		if n.part == n.stmt.X {
+			use(n.part)
+		} else if ident, ok := n.part.(*ast.Ident); ok {
+			define(ident)
+		} else {
+			use(n.part)
+		}
+	case *ast.AssignStmt:
+		use(n.Rhs...)
+		for _, lhs := range n.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok {
+				if n.Tok != token.ASSIGN && n.Tok != token.DEFINE {
+					use(ident)
+				}
+				define(ident)
+			} else {
+				// x[i] = v and p.f = v use x, i and p.
+				use(lhs)
+			}
+		}
+	case *ast.IncDecStmt:
+		use(n.X)
+		define(modifiedBy(n)...)
+	case *ast.ValueSpec:
+		use(n.Values...)
+		define(n.Names...)
+	case *ast.DeclStmt:
+		if decl, ok := n.Decl.(*ast.GenDecl); ok {
+			for _, spec := range decl.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					use(valueSpec.Values...)
+					define(valueSpec.Names...)
+				}
+			}
+		}
+	case *ast.ReturnStmt:
+		use(n.Results...)
+	case *ast.DeferStmt:
+		use(n.Call.Args...)
+		if lit, ok := n.Call.Fun.(*ast.FuncLit); ok {
+			define(capturedWrites(lit, info)...)
+		}
+	case *ast.GoStmt:
+		use(n.Call.Args...)
+		if lit, ok := n.Call.Fun.(*ast.FuncLit); ok {
+			define(capturedWrites(lit, info)...)
+		}
+	case *ast.ExprStmt:
+		use(n.X)
+	case *ast.SendStmt:
+		use(n.Chan, n.Value)
+	case ast.Expr:
+		use(n)
+	}
+	return defs, uses
+}
+
+// annotateDefsUses appends the variables every node of cg defines and uses
+// to its label in the DOT output dot, as in "x = a + b  def={x} use={a, b}".
+// Empty sets are left out.
+func annotateDefsUses(dot string, cg *cfg.CFG, v *varNamer, info *types.Info) string {
+	annotations := make(map[string]string)
+	for _, block := range cg.Blocks {
+		for i, node := range block.Nodes {
+			defs, uses := defsUses(asRangeHeader(block, node), v, info)
+			annotation := ""
+			if len(defs) > 0 {
+				annotation += " def={" + strings.Join(uniqueSorted(defs), ", ") + "}"
+			}
+			if len(uses) > 0 {
+				annotation += " use={" + strings.Join(uniqueSorted(uses), ", ") + "}"
+			}
+			if annotation != "" {
+				annotations[fmt.Sprintf("block_%d_node_%d", block.Index, i)] = " " + escapeLabel(annotation)
+			}
+		}
+	}
+	return dotNodeLabel.ReplaceAllStringFunc(dot, func(stmt string) string {
+		match := dotNodeLabel.FindStringSubmatch(stmt)
+		return fmt.Sprintf("  %s [label=\"%s%s\"];", match[1], match[2], annotations[match[1]])
+	})
+}
+
+func uniqueSorted(names []string) []string {
+	names = slices.Clone(names)
+	slices.Sort(names)
+	return slices.Compact(names)
+}
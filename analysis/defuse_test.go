@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDefUseEdges(t *testing.T) {
+	opts := DefaultOptions()
+	opts.DefUse = true
+	tests := []struct {
+		name, body string
+		want       []dotEdge // def label, use label, variable
+	}{
+		{"straight line", "x := 1\ny := x\nreturn y", []dotEdge{
+			{"x = 1", "y = x", "x"},
+			{"y = x", "Return: y", "y"},
+		}},
+		{"redefinition", "x := 1\nx = 2\nreturn x", []dotEdge{
+			{"x = 2", "Return: x", "x"},
+		}},
+		{"merge after if", "x := 1\nif c {\n\tx = 2\n}\nreturn x", []dotEdge{
+			{"x = 1", "Return: x", "x"},
+			{"x = 2", "Return: x", "x"},
+		}},
+		{"both arms", "var x int\nif c {\n\tx = 1\n} else {\n\tx = 2\n}\nreturn x", []dotEdge{
+			{"x = 1", "Return: x", "x"},
+			{"x = 2", "Return: x", "x"},
+		}},
+		{"loop", "s := 0\nfor i := 0; i < 3; i++ {\n\ts += i\n}\nreturn s", []dotEdge{
+			// genDot labels s += i as "s = i".
+			{"s = 0", "s = i", "s"},
+			{"s = i", "s = i", "s"},
+			{"s = 0", "Return: s", "s"},
+			{"s = i", "Return: s", "s"},
+		}},
+		{"parameter", "return p", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := dataEdges(snippetDot(t, opts, test.body))
+			// Only the edges between the statements above are compared;
+			// the loop counter has edges of its own.
+			got = slices.DeleteFunc(got, func(e dotEdge) bool { return e.attrs == "i" })
+			sortEdges := func(edges []dotEdge) {
+				slices.SortFunc(edges, func(a, b dotEdge) int {
+					return strings.Compare(a.from+"\x00"+a.to, b.from+"\x00"+b.to)
+				})
+			}
+			sortEdges(got)
+			sortEdges(test.want)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("def-use edges %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateDefsUses(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string // node labels
+	}{
+		{"x := a + b", []string{"x = a + b  def={x} use={a, b}"}},
+		{"x += y", []string{"x = y  def={x} use={x, y}"}},
+		{"f(a, a)", []string{"f(a, a)  use={a}"}},
+		{"s[i] = v", []string{"s[i] = v  use={i, s, v}"}},
+		{"i++", []string{"i ++  def={i} use={i}"}},
+		{"ch <- v", []string{"ch <- v  use={ch, v}"}},
+		{"_ = f()", []string{"_ = f()"}},
+		{"return", []string{"Return: "}},
+	}
+	for _, test := range tests {
+		t.Run(test.body, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			cg := newCFG(fn)
+			dot, _ := defaultAnalyzer().genDot(fset, fn, cg, nil)
+			dot = annotateDefsUses(dot, cg, newVarNamer(nil), nil)
+			for _, label := range test.want {
+				if !hasLabel(dot, label) {
+					t.Errorf("no node labeled %q:\n%s", label, dot)
+				}
+			}
+		})
+	}
+}
+
+func TestDataFlowFindings(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []string // rule and line of every finding
+	}{
+		{"overwritten", "x := 1\nx = 2\nprintln(x)", []string{"dead-store 1"}},
+		{"read", "x := 1\nprintln(x)", nil},
+		{"last assignment", "x := 1\nprintln(x)\nx = 2", []string{"dead-store 3"}},
+		{"overwritten in both arms", "x := 0\nif c {\n\tx = 1\n} else {\n\tx = 2\n}\nprintln(x)", []string{"dead-store 1"}},
+		{"overwritten in one arm", "x := 0\nif c {\n\tx = 1\n}\nprintln(x)", nil},
+		{"loop", "s := 0\nfor i := 0; i < 3; i++ {\n\ts += i\n}\nprintln(s)", nil},
+		{"zero declaration", "var x int\nx = 1\nprintln(x)", nil},
+		{"closure", "x := 1\nf := func() {\n\tprintln(x)\n}\nx = 2\nf()", nil},
+		{"address taken", "x := 1\np := &x\nx = 2\nprintln(*p)", nil},
+		{"package variable", "count = 1", nil},
+		{"defined in one arm", "if c {\n\tx := 1\n\t_ = x\n}\nprintln(x)", []string{"use-before-def 5"}},
+		{"outer variable in declaration", "path := path.Clean(p)\nprintln(path)", nil},
+		{"outer variable before declaration", "println(x)\nx := 1\nprintln(x)", nil},
+		{"struct literal key", "if c {\n\tnext := 1\n\t_ = next\n}\nv := T{next: 2}\nprintln(v)", nil},
+		{"undeclared variable", "p = 2", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			var got []string
+			for _, finding := range dataFlowFindings(fset, fn, newCFG(fn)) {
+				got = append(got, fmt.Sprintf("%s %d", finding.RuleID, finding.Pos.Line))
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("findings %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// dominators computes the immediate dominator of every live block of cg
+// other than the entry, using the iterative algorithm of Cooper, Harvey and
+// Kennedy over the blocks in reverse postorder. A block dominates another
+// when every path from the entry to the latter passes through it.
+func dominators(cg *cfg.CFG) map[int32]int32 {
+	order := executionOrder(cg)
+	if len(order) == 0 {
+		return nil
+	}
+	rpo := make(map[int32]int)
+	preds := make(map[int32][]int32)
+	for i, block := range order {
+		rpo[block.Index] = i
+	}
+	for _, block := range order {
+		for _, succ := range block.Succs {
+			preds[succ.Index] = append(preds[succ.Index], block.Index)
+		}
+	}
+
+	entry := order[0].Index
+	idom := map[int32]int32{entry: entry}
+	intersect := func(a, b int32) int32 {
+		for a != b {
+			for rpo[a] > rpo[b] {
+				a = idom[a]
+			}
+			for rpo[b] > rpo[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, block := range order[1:] {
+			newIdom, found := int32(0), false
+			for _, pred := range preds[block.Index] {
+				if _, processed := idom[pred]; !processed {
+					continue
+				}
+				if !found {
+					newIdom, found = pred, true
+				} else {
+					newIdom = intersect(pred, newIdom)
+				}
+			}
+			if old, ok := idom[block.Index]; found && (!ok || old != newIdom) {
+				idom[block.Index] = newIdom
+				changed = true
+			}
+		}
+	}
+	delete(idom, entry)
+	return idom
+}
+
+func printDominators(w io.Writer, idom map[int32]int32) {
+	blocks := []int32{}
+	for block := range idom {
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintln(w, "Immediate dominators:")
+	for _, block := range blocks {
+		fmt.Fprintf(w, "  block %d: %d\n", block, idom[block])
+	}
+}
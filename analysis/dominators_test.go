@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"maps"
+	"testing"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+func TestDominators(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       map[cfg.BlockKind]cfg.BlockKind // block -> immediate dominator, by kind
+	}{
+		{"if else", "if a > b {\n\tx()\n} else {\n\ty()\n}\nz()", map[cfg.BlockKind]cfg.BlockKind{
+			cfg.KindIfThen: cfg.KindBody,
+			cfg.KindIfElse: cfg.KindBody,
+			cfg.KindIfDone: cfg.KindBody,
+		}},
+		{"loop", "for i < n {\n\tx()\n}\ny()", map[cfg.BlockKind]cfg.BlockKind{
+			cfg.KindForLoop: cfg.KindBody,
+			cfg.KindForBody: cfg.KindForLoop,
+			cfg.KindForDone: cfg.KindForLoop,
+		}},
+		{"if in loop", "for i < n {\n\tif i > 2 {\n\t\tx()\n\t}\n\ty()\n}", map[cfg.BlockKind]cfg.BlockKind{
+			cfg.KindForLoop: cfg.KindBody,
+			cfg.KindForBody: cfg.KindForLoop,
+			cfg.KindForDone: cfg.KindForLoop,
+			cfg.KindIfThen:  cfg.KindForBody,
+			cfg.KindIfDone:  cfg.KindForBody,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, fn := parseSnippet(t, test.body)
+			cg := newCFG(fn)
+			got := make(map[cfg.BlockKind]cfg.BlockKind)
+			for block, dom := range dominators(cg) {
+				got[cg.Blocks[block].Kind] = cg.Blocks[dom].Kind
+			}
+			if !maps.Equal(got, test.want) {
+				t.Errorf("immediate dominators %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1386 @@
+package analysis
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+func (a *analyzer) printCFG(w io.Writer, cg *cfg.CFG) {
+	for _, block := range cg.Blocks {
+		fmt.Fprintf(w, "Block: %s\n", block.String())
+		for _, node := range block.Nodes {
+			switch n := node.(type) {
+			case *ast.DeclStmt:
+				a.printValueSpec(w, n.Decl.(*ast.GenDecl).Specs[0].(*ast.ValueSpec))
+			case *ast.ValueSpec:
+				a.printValueSpec(w, n)
+			case *ast.AssignStmt:
+				a.printAssignStmt(w, n)
+			case *ast.ReturnStmt:
+				a.printReturnStmt(w, n)
+			case *ast.ExprStmt:
+				a.printExpr(w, n.X)
+			case *ast.ParenExpr:
+				a.printExpr(w, n)
+			case *ast.IncDecStmt:
+				a.printIncDecStmt(w, n)
+			case *ast.BinaryExpr:
+				a.printBinaryExpr(w, n)
+			case *ast.CallExpr:
+				a.printCallExpr(w, n)
+			case *ast.SendStmt:
+				fmt.Fprintf(w, " -> Send: %s <- %s\n", a.getValue(n.Chan), a.getValue(n.Value))
+			default:
+				fmt.Fprintf(w, " -> Node (Unhandled): %T\n", node)
+			}
+		}
+		for _, succ := range block.Succs {
+			fmt.Fprintf(w, " -> Successor: %s\n", succ.String())
+		}
+	}
+}
+
+func (a *analyzer) printValueSpec(w io.Writer, valueSpec *ast.ValueSpec) {
+	for i, name := range valueSpec.Names {
+		value := "nil"
+		if i < len(valueSpec.Values) {
+			value = a.getValue(valueSpec.Values[i])
+		}
+		fmt.Fprintf(w, " -> Node: %s = %s\n", name.Name, value)
+	}
+}
+
+func (a *analyzer) printAssignStmt(w io.Writer, assignStmt *ast.AssignStmt) {
+	for i, lhs := range assignStmt.Lhs {
+		if ident, ok := lhs.(*ast.Ident); ok {
+			value := "nil"
+			if rhs := assignedValue(assignStmt, i); rhs != nil {
+				value = a.getValue(rhs)
+			}
+			fmt.Fprintf(w, " -> Node: %s = %s\n", ident.Name, value)
+		}
+	}
+}
+
+// resultNames returns the named results of fn, excluding blank ones.
+func resultNames(fn *ast.FuncDecl) []*ast.Ident {
+	var names []*ast.Ident
+	if fn.Type.Results == nil {
+		return names
+	}
+	for _, field := range fn.Type.Results.List {
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// funcName returns the name of fn qualified by its receiver type for methods:
+// "T.M" for a value and "(*T).M" for a pointer receiver, as in method
+// expressions.
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if _, ok := recv.(*ast.StarExpr); ok {
+		return "(" + types.ExprString(recv) + ")." + fn.Name.Name
+	}
+	return types.ExprString(recv) + "." + fn.Name.Name
+}
+
+// assignedValue returns the expression assigned to the i-th LHS of
+// assignStmt. In a multi-value assignment like "a, b := f()" every LHS shares
+// the single call on the right-hand side.
+func assignedValue(assignStmt *ast.AssignStmt, i int) ast.Expr {
+	if len(assignStmt.Lhs) == len(assignStmt.Rhs) {
+		return assignStmt.Rhs[i]
+	}
+	if len(assignStmt.Rhs) == 1 {
+		return assignStmt.Rhs[0]
+	}
+	return nil
+}
+
+// refersTo reports whether expr refers to the variable ident. Without type
+// information identifiers are compared by name; selected fields and methods
+// never match.
+func refersTo(expr ast.Expr, ident *ast.Ident, info *types.Info) bool {
+	if expr == nil {
+		return false
+	}
+	var obj types.Object
+	if info != nil {
+		obj = info.ObjectOf(ident)
+	}
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.SelectorExpr:
+			found = found || refersTo(n.X, ident, info)
+			return false
+		case *ast.Ident:
+			if obj != nil {
+				found = found || info.ObjectOf(n) == obj
+			} else {
+				found = found || n.Name == ident.Name
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+func (a *analyzer) printReturnStmt(w io.Writer, returnStmt *ast.ReturnStmt) {
+	values := []string{}
+	for _, result := range returnStmt.Results {
+		values = append(values, a.getValue(result))
+	}
+	fmt.Fprintf(w, " -> Return: %s\n", values)
+}
+
+func (a *analyzer) printExpr(w io.Writer, expr ast.Expr) {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		a.printBinaryExpr(w, e)
+	case *ast.CallExpr:
+		a.printCallExpr(w, e)
+	default:
+		fmt.Fprintf(w, " -> Node: %s\n", a.getValue(expr))
+	}
+}
+
+func (a *analyzer) printIncDecStmt(w io.Writer, incDecStmt *ast.IncDecStmt) {
+	fmt.Fprintf(w, " -> Node: %s %s\n", a.getValue(incDecStmt.X), incDecStmt.Tok.String())
+}
+
+func (a *analyzer) printBinaryExpr(w io.Writer, binaryExpr *ast.BinaryExpr) {
+	fmt.Fprintf(w, " -> Node: %s %s %s\n", a.getValue(binaryExpr.X), binaryExpr.Op.String(), a.getValue(binaryExpr.Y))
+}
+
+func (a *analyzer) printCallExpr(w io.Writer, callExpr *ast.CallExpr) {
+	fmt.Fprintf(w, " -> Node: %s\n", a.getValue(callExpr))
+}
+
+// getValue renders expr as source-like text without any options, for use
+// in messages and names.
+func getValue(expr ast.Expr) string {
+	return (*analyzer)(nil).getValue(expr)
+}
+
+func (a *analyzer) getValue(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		// The literal as written, e.g. 3i, '\'' or "a\tb"; quotes and
+		// backslashes are escaped by escapeLabel when it becomes a label.
+		return e.Value
+	case *ast.Ident:
+		return e.Name
+	case *ast.BinaryExpr:
+		return fmt.Sprintf("%s %s %s", a.getOperand(e.X, e.Op, false), e.Op.String(), a.getOperand(e.Y, e.Op, true))
+	case *ast.CallExpr:
+		args := []string{}
+		for _, arg := range e.Args {
+			args = append(args, a.getValue(arg))
+		}
+		// A spread call, f(xs...), passes a slice as the variadic arguments.
+		if e.Ellipsis.IsValid() {
+			args[len(args)-1] += "..."
+		}
+		return fmt.Sprintf("%s(%s)", a.getValue(e.Fun), strings.Join(args, ", "))
+	case *ast.SelectorExpr:
+		if label, ok := a.abbreviatedSelector(e); ok {
+			return label
+		}
+		return fmt.Sprintf("%s.%s", a.getValue(e.X), e.Sel.Name)
+	case *ast.UnaryExpr:
+		return e.Op.String() + a.getValue(e.X)
+	case *ast.ParenExpr:
+		return fmt.Sprintf("(%s)", a.getValue(e.X))
+	case *ast.StarExpr:
+		return "*" + a.getValue(e.X)
+	case *ast.IndexExpr:
+		return fmt.Sprintf("%s[%s]", a.getValue(e.X), a.getValue(e.Index))
+	case *ast.SliceExpr:
+		bounds := []string{}
+		for _, bound := range []ast.Expr{e.Low, e.High, e.Max} {
+			if bound != nil {
+				bounds = append(bounds, a.getValue(bound))
+			} else {
+				bounds = append(bounds, "")
+			}
+		}
+		if !e.Slice3 {
+			bounds = bounds[:2]
+		}
+		return fmt.Sprintf("%s[%s]", a.getValue(e.X), strings.Join(bounds, ":"))
+	case *ast.IndexListExpr:
+		indices := []string{}
+		for _, index := range e.Indices {
+			indices = append(indices, a.getValue(index))
+		}
+		return fmt.Sprintf("%s[%s]", a.getValue(e.X), strings.Join(indices, ", "))
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return "[]" + a.getValue(e.Elt)
+		}
+		return fmt.Sprintf("[%s]%s", a.getValue(e.Len), a.getValue(e.Elt))
+	case *ast.MapType:
+		return fmt.Sprintf("map[%s]%s", a.getValue(e.Key), a.getValue(e.Value))
+	case *ast.CompositeLit:
+		elts := []string{}
+		for _, elt := range e.Elts {
+			elts = append(elts, a.getValue(elt))
+		}
+		typ := ""
+		if e.Type != nil {
+			typ = a.getValue(e.Type)
+		}
+		return fmt.Sprintf("%s{%s}", typ, strings.Join(elts, ", "))
+	case *ast.KeyValueExpr:
+		return fmt.Sprintf("%s: %s", a.getValue(e.Key), a.getValue(e.Value))
+	case *ast.StructType:
+		if e.Fields == nil || len(e.Fields.List) == 0 {
+			return "struct{}"
+		}
+		return "struct{…}"
+	case *ast.FuncType:
+		params := "func(" + a.fieldList(e.Params) + ")"
+		if e.Results == nil {
+			return params
+		}
+		if results := e.Results.List; len(results) == 1 && len(results[0].Names) == 0 {
+			return params + " " + a.getValue(results[0].Type)
+		}
+		return fmt.Sprintf("%s (%s)", params, a.fieldList(e.Results))
+	case *ast.FuncLit:
+		return a.getValue(e.Type) + " {…}"
+	case *ast.Ellipsis:
+		// The type of a variadic parameter, ...int, or the length of an
+		// array literal [...]int{...}, which has no element type.
+		if e.Elt == nil {
+			return "..."
+		}
+		return "..." + a.getValue(e.Elt)
+	case *ast.ChanType:
+		switch e.Dir {
+		case ast.SEND:
+			return "chan<- " + a.getValue(e.Value)
+		case ast.RECV:
+			return "<-chan " + a.getValue(e.Value)
+		default:
+			return "chan " + a.getValue(e.Value)
+		}
+	default:
+		a.recordUnhandled(expr)
+		return fmt.Sprintf("%T", expr)
+	}
+}
+
+// fieldList renders the parameters or results in fields, e.g. "a, b int, s string".
+func (a *analyzer) fieldList(fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+	parts := []string{}
+	for _, field := range fields.List {
+		names := []string{}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		if len(names) == 0 {
+			parts = append(parts, a.getValue(field.Type))
+		} else {
+			parts = append(parts, strings.Join(names, ", ")+" "+a.getValue(field.Type))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// escapeLabel escapes s for use inside a quoted DOT label.
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+// getOperand renders an operand of a binary expression with operator op,
+// parenthesizing a nested binary expression that binds less tightly than op
+// (or equally tightly on the right, since Go operators are left-associative).
+// Parsed code keeps its explicit parentheses as ParenExpr; this only matters
+// for ASTs whose grouping is implicit in their shape.
+func (a *analyzer) getOperand(expr ast.Expr, op token.Token, right bool) string {
+	value := a.getValue(expr)
+	if inner, ok := expr.(*ast.BinaryExpr); ok {
+		if prec := inner.Op.Precedence(); prec < op.Precedence() || (right && prec == op.Precedence()) {
+			return "(" + value + ")"
+		}
+	}
+	return value
+}
+
+// getStmt renders a simple statement such as the init or post clause of a
+// for loop.
+func (a *analyzer) getStmt(stmt ast.Stmt) string {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		lhs := []string{}
+		for _, expr := range s.Lhs {
+			lhs = append(lhs, a.getValue(expr))
+		}
+		rhs := []string{}
+		for _, expr := range s.Rhs {
+			rhs = append(rhs, a.getValue(expr))
+		}
+		return fmt.Sprintf("%s %s %s", strings.Join(lhs, ", "), s.Tok.String(), strings.Join(rhs, ", "))
+	case *ast.IncDecStmt:
+		return a.getValue(s.X) + s.Tok.String()
+	case *ast.ExprStmt:
+		return a.getValue(s.X)
+	default:
+		return fmt.Sprintf("%T", stmt)
+	}
+}
+
+// forLabel renders the header of a for loop, e.g. "for i := 0; i < n; i++".
+func (a *analyzer) forLabel(forStmt *ast.ForStmt) string {
+	cond := ""
+	if forStmt.Cond != nil {
+		cond = a.getValue(forStmt.Cond)
+	}
+	if forStmt.Init == nil && forStmt.Post == nil {
+		return strings.TrimSpace("for " + cond)
+	}
+	init, post := "", ""
+	if forStmt.Init != nil {
+		init = a.getStmt(forStmt.Init)
+	}
+	if forStmt.Post != nil {
+		post = a.getStmt(forStmt.Post)
+	}
+	return fmt.Sprintf("for %s; %s; %s", init, cond, post)
+}
+
+// modifiedBy returns the identifiers written by an assignment or inc/dec
+// statement.
+func modifiedBy(stmt ast.Stmt) []*ast.Ident {
+	var idents []*ast.Ident
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range s.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+				idents = append(idents, ident)
+			}
+		}
+	case *ast.IncDecStmt:
+		if ident, ok := s.X.(*ast.Ident); ok {
+			idents = append(idents, ident)
+		}
+	}
+	return idents
+}
+
+// capturedWrites returns the identifiers of variables declared outside lit
+// that lit assigns to, such as the named result err in
+// "defer func() { err = wrap(err) }()". Without type information variables
+// are told apart by name, so an outer variable that lit also declares is
+// taken to be local.
+func capturedWrites(lit *ast.FuncLit, info *types.Info) []*ast.Ident {
+	locals := make(map[string]bool)
+	isLocal := func(ident *ast.Ident) bool {
+		if info != nil {
+			if obj := info.ObjectOf(ident); obj != nil {
+				return obj.Pos() >= lit.Pos() && obj.Pos() < lit.End()
+			}
+		}
+		return locals[ident.Name]
+	}
+	for _, list := range []*ast.FieldList{lit.Type.Params, lit.Type.Results} {
+		if list != nil {
+			for _, field := range list.List {
+				for _, name := range field.Names {
+					locals[name.Name] = true
+				}
+			}
+		}
+	}
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			if s.Tok == token.DEFINE {
+				for _, ident := range modifiedBy(s) {
+					locals[ident.Name] = true
+				}
+			}
+		case *ast.RangeStmt:
+			if s.Tok == token.DEFINE {
+				for _, expr := range []ast.Expr{s.Key, s.Value} {
+					if ident, ok := expr.(*ast.Ident); ok {
+						locals[ident.Name] = true
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for _, name := range s.Names {
+				locals[name.Name] = true
+			}
+		}
+		return true
+	})
+
+	var idents []*ast.Ident
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		stmt, ok := n.(ast.Stmt)
+		if !ok {
+			return true
+		}
+		if assign, ok := stmt.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
+			return true
+		}
+		for _, ident := range modifiedBy(stmt) {
+			if !isLocal(ident) {
+				idents = append(idents, ident)
+			}
+		}
+		return true
+	})
+	return idents
+}
+
+// targetBase returns the variable an assignment to the element, field or
+// pointee expr stores into: a for a[i], p for p.f and *p, and nil for the
+// blank identifier or when no variable is involved, as in f().x.
+func targetBase(expr ast.Expr) *ast.Ident {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if e.Name == "_" {
+			return nil
+		}
+		return e
+	case *ast.IndexExpr:
+		return targetBase(e.X)
+	case *ast.SelectorExpr:
+		return targetBase(e.X)
+	case *ast.StarExpr:
+		return targetBase(e.X)
+	}
+	return nil
+}
+
+// indexUses returns the names of the variables used by the index and slice
+// expressions and the array lengths within node, so that "t := s[i:j]"
+// yields s, i and j, and "var buf [n]byte" yields n. Each name is returned
+// once.
+func indexUses(v *varNamer, node ast.Node) []string {
+	var names []string
+	seen := make(map[string]bool)
+	use := func(parts ...ast.Expr) {
+		for _, part := range parts {
+			for _, name := range condVars(v, part) {
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.IndexExpr:
+			use(e.X, e.Index)
+		case *ast.SliceExpr:
+			use(e.X, e.Low, e.High, e.Max)
+		case *ast.ArrayType:
+			use(e.Len)
+		}
+		return true
+	})
+	return names
+}
+
+// channelUses returns the names of the variables used by the channel sends
+// and receives within node, so that "ch <- x" yields ch and x, and
+// "v := <-ch" yields ch. Each name is returned once.
+func channelUses(v *varNamer, node ast.Node) []string {
+	var names []string
+	seen := make(map[string]bool)
+	use := func(parts ...ast.Expr) {
+		for _, part := range parts {
+			for _, name := range condVars(v, part) {
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.SendStmt:
+			use(e.Chan, e.Value)
+		case *ast.UnaryExpr:
+			if e.Op == token.ARROW {
+				use(e.X)
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// varNamer maps identifiers to the variable names used in the data-flow graph
+// and the Chepin sets. Without type information the identifier name is used
+// as is; with it, distinct objects sharing a name (shadowing) get distinct
+// names such as "err#2".
+type varNamer struct {
+	info  *types.Info
+	names map[types.Object]string
+	seen  map[string]int
+}
+
+func newVarNamer(info *types.Info) *varNamer {
+	return &varNamer{info: info, names: make(map[types.Object]string), seen: make(map[string]int)}
+}
+
+func (v *varNamer) name(ident *ast.Ident) string {
+	if v.info == nil {
+		return ident.Name
+	}
+	obj := v.info.ObjectOf(ident)
+	if obj == nil {
+		return ident.Name
+	}
+	if name, ok := v.names[obj]; ok {
+		return name
+	}
+	v.seen[ident.Name]++
+	name := ident.Name
+	if n := v.seen[ident.Name]; n > 1 {
+		name = fmt.Sprintf("%s#%d", ident.Name, n)
+	}
+	v.names[obj] = name
+	return name
+}
+
+// condVars returns the names of the variables referenced by the condition
+// cond, so that "a && b > c" yields a, b and c. Called functions, selected
+// field names and the predeclared constants are not variables.
+func condVars(v *varNamer, cond ast.Expr) []string {
+	var names []string
+	if cond == nil {
+		return names
+	}
+	ast.Inspect(cond, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.CallExpr:
+			if _, ok := e.Fun.(*ast.Ident); !ok {
+				names = append(names, condVars(v, e.Fun)...)
+			}
+			for _, arg := range e.Args {
+				names = append(names, condVars(v, arg)...)
+			}
+			return false
+		case *ast.SelectorExpr:
+			names = append(names, condVars(v, e.X)...)
+			return false
+		case *ast.CompositeLit:
+			// The type is not a variable, and neither are the field names
+			// keying the elements of a struct literal. Only map keys can
+			// be variables; array and slice indices are constants.
+			_, isMap := e.Type.(*ast.MapType)
+			for _, elt := range e.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok && !isMap {
+					if _, ok := kv.Key.(*ast.Ident); ok {
+						elt = kv.Value
+					}
+				}
+				names = append(names, condVars(v, elt)...)
+			}
+			return false
+		case *ast.FuncLit, *ast.FuncType, *ast.MapType, *ast.ChanType, *ast.StructType, *ast.InterfaceType:
+			// Parameter and field names in types are not variables.
+			return false
+		case *ast.Ident:
+			switch e.Name {
+			case "true", "false", "nil", "_":
+			default:
+				names = append(names, v.name(e))
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// exprName returns the data-flow name of expr: the resolved variable name for
+// identifiers and the rendered value otherwise.
+func (v *varNamer) exprName(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return v.name(ident)
+	}
+	return getValue(expr)
+}
+
+// chepinSets holds the variable sets of Chepin's metric collected by genDot.
+type chepinSets struct {
+	input        map[string]int  // P
+	modified     map[string]bool // M
+	control      map[string]int  // C, number of decision points using the variable
+	unused       map[string]bool // T
+	controlSites map[string][]token.Position
+	vocabulary   int // distinct variables declared or used in the function
+}
+
+// chepinScore computes Chepin's metric Q = P + 2M + 3C + 0.5T of c, with
+// the weights of the options.
+func (a *analyzer) chepinScore(c *chepinSets) float64 {
+	o := a.opts
+	return o.ChepinP*float64(len(c.input)) + o.ChepinM*float64(len(c.modified)) + o.ChepinC*float64(len(c.control)) + o.ChepinT*float64(len(c.unused))
+}
+
+// customChepinWeights reports whether any Chepin weight differs from the
+// standard formula.
+func (a *analyzer) customChepinWeights() bool {
+	o, d := a.opts, DefaultOptions()
+	return o.ChepinP != d.ChepinP || o.ChepinM != d.ChepinM || o.ChepinC != d.ChepinC || o.ChepinT != d.ChepinT
+}
+
+func (a *analyzer) printChepin(w io.Writer, c *chepinSets) {
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintln(w, "P: ", c.input)
+	fmt.Fprintln(w, "M: ", c.modified)
+	fmt.Fprintln(w, "C: ", c.control)
+	fmt.Fprintln(w, "T: ", c.unused)
+	fmt.Fprintln(w, "Chepin score: ", a.chepinScore(c))
+	if a.customChepinWeights() {
+		o := a.opts
+		fmt.Fprintf(w, "  (weights: P=%g M=%g C=%g T=%g)\n", o.ChepinP, o.ChepinM, o.ChepinC, o.ChepinT)
+	}
+	printControlVars(w, c.control, c.controlSites)
+}
+
+func printCyclomatic(w io.Writer, cg *cfg.CFG) {
+	cyclomaticComplexity, numEdges, numNodes := cyclomatic(cg)
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintln(w, "Cyclomatic Complexity: ", cyclomaticComplexity)
+	fmt.Fprintf(w, "Number of Edges: %d.\n", numEdges)
+	fmt.Fprintf(w, "Number of Nodes: %d.\n", numNodes)
+}
+
+// genDot renders cg, the CFG of fn, as a DOT graph and collects the Chepin
+// variable sets of the function along the way. The output is fully
+// determined by the source: go/cfg appends every block with Index set to its
+// position in cg.Blocks, so the blocks are emitted by index, the nodes of a
+// block in order, then the block's successor edges in Succs order with
+// non-empty successors first, and finally the data-flow edges grouped by
+// variable name in sorted order.
+func (a *analyzer) genDot(fset *token.FileSet, fn *ast.FuncDecl, cg *cfg.CFG, info *types.Info) (string, *chepinSets) {
+	// CHEPIN
+	// Sets to keep track of variables
+	inputVars := make(map[string]int)     // P
+	modifiedVars := make(map[string]bool) // M
+	controlVars := make(map[string]int)   // C, number of decision points using the variable
+	unusedVars := make(map[string]bool)   // T
+	controlSites := make(map[string][]token.Position)
+
+	// markControl records the variables referenced by the decision point at
+	// pos, counting each variable once per decision point.
+	markControl := func(pos token.Pos, names ...string) {
+		seen := make(map[string]bool)
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			controlVars[name]++
+			controlSites[name] = append(controlSites[name], fset.Position(pos))
+		}
+	}
+
+	a.fset = fset
+	recursive := recursiveCalls(fn, info)
+	dot := "digraph G {\n" + a.colors().nodeDefaults()
+	// emit appends a line to the DOT text. With MetricsOnly the walk
+	// below only collects the variable sets.
+	emit := func(format string, args ...any) {
+		if !a.opts.MetricsOnly {
+			dot += fmt.Sprintf(format, args...)
+		}
+	}
+	variables := make(map[string][]string)
+	namer := newVarNamer(info)
+	nodeIDs := cfgNodeIDs(cg)
+
+	// loopLabel returns label for the condition cond of block, except that
+	// the condition of a for loop heads its ForLoop block and is shown as the
+	// whole loop clause; the loop's post statement is then counted.
+	loopLabel := func(block *cfg.Block, cond ast.Expr, label string) string {
+		forStmt, ok := block.Stmt.(*ast.ForStmt)
+		if !ok || block.Kind != cfg.KindForLoop || forStmt.Cond != cond {
+			return label
+		}
+		for _, name := range modifiedBy(forStmt.Post) {
+			modifiedVars[namer.name(name)] = true
+		}
+		return a.forLabel(forStmt)
+	}
+
+	// declare records every name of spec as declared at nodeID and returns
+	// the node label, e.g. "a, b, c int" or "x, y = 1, 2".
+	declare := func(spec *ast.ValueSpec, nodeID string) string {
+		names := []string{}
+		for _, name := range spec.Names {
+			names = append(names, name.Name)
+			if name.Name == "_" {
+				continue
+			}
+			varName := namer.name(name)
+			variables[varName] = append(variables[varName], nodeID)
+			inputVars[varName]++
+		}
+		label := strings.Join(names, ", ")
+		if spec.Type != nil {
+			label += " " + a.getValue(spec.Type)
+		}
+		if len(spec.Values) > 0 {
+			values := []string{}
+			for _, value := range spec.Values {
+				values = append(values, a.getValue(value))
+			}
+			label += " = " + strings.Join(values, ", ")
+		}
+		return label
+	}
+
+	// closureWrites records the enclosing variables assigned by the function
+	// literal that call, a deferred or go statement's call, invokes as
+	// modified at nodeID.
+	closureWrites := func(call *ast.CallExpr, nodeID string) {
+		lit, ok := call.Fun.(*ast.FuncLit)
+		if !ok {
+			return
+		}
+		for _, ident := range capturedWrites(lit, info) {
+			varName := namer.name(ident)
+			if uses := variables[varName]; len(uses) == 0 || uses[len(uses)-1] != nodeID {
+				variables[varName] = append(variables[varName], nodeID)
+			}
+			modifiedVars[varName] = true
+		}
+	}
+	for _, block := range cg.Blocks {
+		if !block.Live {
+			continue
+		}
+		blockID := fmt.Sprintf("block_%d", block.Index)
+		// DEBUG
+		//blockLabel := block.String()
+		//emit("  %s [label=\"%s\"];\n", blockID, blockLabel)
+		var prevNodeID string
+		var lastNodeID string
+		// ownEdges is set when the last node drew its own branch edges, which
+		// then replace the block-level successor edges.
+		var ownEdges bool
+		// DEBUG basic blocks
+		/*		if len(block.Nodes) == 0 {
+				for _, succ := range block.Succs {
+					succID := fmt.Sprintf("block_%d", succ.Index)
+					color := a.colors().branchColor(succ.Kind)
+					emit("  %s -> %s [color=\"%s\"];\n", blockID, succID, color)
+				}
+			} */
+		for i, node := range block.Nodes {
+			nodeID := fmt.Sprintf("%s_node_%d", blockID, i)
+			ownEdges = false
+			switch n := asRangeHeader(block, node).(type) {
+			case *rangeHeader:
+				// The ranged expression controls the loop; the key and
+				// value are loop variables modified on every iteration.
+				emit("  %s [label=\"%s\"];\n", nodeID, escapeLabel(a.rangeLabel(n, rangesOverInt(n.stmt, info))))
+				if n.part == n.stmt.X {
+					markControl(n.Pos(), condVars(namer, n.part)...)
+					for _, varName := range condVars(namer, n.part) {
+						if uses := variables[varName]; len(uses) == 0 || uses[len(uses)-1] != nodeID {
+							variables[varName] = append(variables[varName], nodeID)
+						}
+					}
+				} else if ident, ok := n.part.(*ast.Ident); ok && ident.Name != "_" {
+					varName := namer.name(ident)
+					variables[varName] = append(variables[varName], nodeID)
+					modifiedVars[varName] = true
+				} else if base := targetBase(n.part); !ok && base != nil {
+					// "for k, m[k] = range xs" stores into m.
+					varName := namer.name(base)
+					if uses := variables[varName]; len(uses) == 0 || uses[len(uses)-1] != nodeID {
+						variables[varName] = append(variables[varName], nodeID)
+					}
+					modifiedVars[varName] = true
+				}
+			case *ast.ValueSpec:
+				emit("  %s [label=\"%s\"];\n", nodeID, escapeLabel(declare(n, nodeID)))
+			case *ast.DeclStmt:
+				labels := []string{}
+				for _, spec := range n.Decl.(*ast.GenDecl).Specs {
+					if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+						labels = append(labels, declare(valueSpec, nodeID))
+					}
+				}
+				emit("  %s [label=\"%s\"];\n", nodeID, escapeLabel(strings.Join(labels, "; ")))
+			case *ast.AssignStmt:
+				names := []string{}
+				values := []string{}
+				for j, lhs := range n.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						rhs := assignedValue(n, j)
+						value := "nil"
+						if rhs != nil {
+							value = a.getValue(rhs)
+						}
+						names = append(names, ident.Name)
+						if len(n.Lhs) == len(n.Rhs) || len(values) == 0 {
+							values = append(values, value)
+						}
+						// Assignments to the blank identifier are discards.
+						if ident.Name == "_" {
+							continue
+						}
+						varName := namer.name(ident)
+						variables[varName] = append(variables[varName], nodeID)
+						inputVars[varName]++
+						// A variable whose new value is computed, in particular
+						// from its old value as in x += 1 or x = f(x), is modified.
+						_, isBinaryExpr := rhs.(*ast.BinaryExpr)
+						if isBinaryExpr || n.Tok != token.ASSIGN && n.Tok != token.DEFINE || refersTo(rhs, ident, info) {
+							modifiedVars[varName] = true
+						}
+					} else {
+						// Storing into an element, field or pointee, as in
+						// a[i] = v, modifies the variable holding it.
+						names = append(names, a.getValue(lhs))
+						if rhs := assignedValue(n, j); rhs != nil && (len(n.Lhs) == len(n.Rhs) || len(values) == 0) {
+							values = append(values, a.getValue(rhs))
+						}
+						if base := targetBase(lhs); base != nil {
+							varName := namer.name(base)
+							if uses := variables[varName]; len(uses) == 0 || uses[len(uses)-1] != nodeID {
+								variables[varName] = append(variables[varName], nodeID)
+							}
+							modifiedVars[varName] = true
+						}
+					}
+				}
+				if len(names) > 0 {
+					emit("  %s [label=\"%s = %s\"];\n", nodeID, escapeLabel(strings.Join(names, ", ")), escapeLabel(strings.Join(values, ", ")))
+				}
+			case *ast.ReturnStmt:
+				values := []string{}
+				for _, result := range n.Results {
+					values = append(values, a.getValue(result))
+					varName := namer.exprName(result)
+					variables[varName] = append(variables[varName], nodeID)
+				}
+				label := strings.Join(values, ", ")
+				// A naked return in a function with named results returns
+				// the current values of those results.
+				if names := resultNames(fn); len(n.Results) == 0 && len(names) > 0 {
+					for _, name := range names {
+						values = append(values, name.Name)
+						varName := namer.name(name)
+						variables[varName] = append(variables[varName], nodeID)
+					}
+					label = "(" + strings.Join(values, ", ") + ")"
+				}
+				emit("  %s [label=\"Return: %s\"];\n", nodeID, escapeLabel(label))
+			case *ast.ExprStmt:
+				switch e := n.X.(type) {
+				case *ast.BinaryExpr:
+					emit("  %s [label=\"%s\"];\n", nodeID, escapeLabel(a.getValue(e)))
+				case *ast.CallExpr:
+					emit("  %s [label=\"%s\"];\n", nodeID, escapeLabel(a.getValue(e)))
+				default:
+					label := escapeLabel(a.getValue(n.X))
+					emit("  %s [label=\"%s\"];\n", nodeID, label)
+				}
+			case *ast.DeferStmt:
+				emit("  %s [label=\"defer %s\"];\n", nodeID, escapeLabel(a.getValue(n.Call)))
+				// A deferred closure runs when the function returns, so
+				// what it assigns to enclosing variables, typically named
+				// results, is the function's output.
+				closureWrites(n.Call, nodeID)
+			case *ast.GoStmt:
+				emit("  %s [label=\"go %s\"];\n", nodeID, escapeLabel(a.getValue(n.Call)))
+				// A goroutine's closure may assign enclosing variables at
+				// any time after this node.
+				closureWrites(n.Call, nodeID)
+			case *ast.SendStmt:
+				emit("  %s [label=\"%s <- %s\"];\n", nodeID, escapeLabel(a.getValue(n.Chan)), escapeLabel(a.getValue(n.Value)))
+			case *ast.IncDecStmt:
+				emit("  %s [label=\"%s %s\"];\n", nodeID, escapeLabel(a.getValue(n.X)), n.Tok.String())
+				for _, name := range modifiedBy(n) {
+					varName := namer.name(name)
+					variables[varName] = append(variables[varName], nodeID)
+					modifiedVars[varName] = true
+				}
+			case *ast.BinaryExpr:
+				label := fmt.Sprintf("%s %s %s", a.getValue(n.X), n.Op.String(), a.getValue(n.Y))
+				emit("  %s [label=\"%s\"];\n", nodeID, escapeLabel(loopLabel(block, n, label)))
+				// Mark the variables beneath compound operands such as
+				// (a+b) rather than the rendered operand.
+				markControl(n.Pos(), condVars(namer, n)...)
+			case *ast.CallExpr:
+				emit("  %s [label=\"%s\"];\n", nodeID, escapeLabel(a.getValue(n)))
+			case *ast.SelectorExpr:
+				emit("  %s [label=\"%s\"];\n", nodeID, escapeLabel(a.getValue(n)))
+			case *ast.Ident, *ast.BasicLit, *ast.UnaryExpr, *ast.IndexExpr, *ast.StarExpr, *ast.ParenExpr:
+				// A condition, switch tag or case value that is a bare
+				// operand, as in "if ok" or "switch x"; its variables decide
+				// the branch. A parenthesized one keeps its parentheses,
+				// whatever it wraps.
+				expr := n.(ast.Expr)
+				emit("  %s [label=\"%s\"];\n", nodeID, escapeLabel(loopLabel(block, expr, a.getValue(expr))))
+				markControl(expr.Pos(), condVars(namer, expr)...)
+			default:
+				a.recordUnhandled(node)
+				emit("  %s [label=\"(Unhandled): %T\"];\n", nodeID, node)
+			}
+			// A node calling the function itself is dashed and linked back
+			// to the entry.
+			if len(recursive) > 0 && containsCall(node, recursive) {
+				emit("  %s [style=\"%s\" color=\"%s\"];\n", nodeID, a.colors().recursionStyle(), a.colors().recursion)
+				emit("  %s -> block_0_node_0 [style=dashed color=\"%s\" label=\"recursion\" constraint=false];\n", nodeID, a.colors().recursion)
+			}
+			// The operands of index and slice expressions and of channel
+			// sends and receives are uses of their variables.
+			for _, varName := range append(indexUses(namer, node), channelUses(namer, node)...) {
+				inputVars[varName]++
+				if uses := variables[varName]; len(uses) == 0 || uses[len(uses)-1] != nodeID {
+					variables[varName] = append(variables[varName], nodeID)
+				}
+			}
+			if prevNodeID != "" {
+				emit("  %s -> %s;\n", prevNodeID, nodeID)
+			}
+			// DEBUG
+			/* else {
+				emit("  %s -> %s;\n", blockID, nodeID)
+			} */
+			prevNodeID = nodeID
+			lastNodeID = nodeID
+		}
+		if ifStmt := ifStmtOf(block); ifStmt != nil && a.opts.IfEdges == "ast" {
+			emit("%s", a.astIfEdges(cg, block, ifStmt, lastNodeID, nodeIDs))
+			ownEdges = true
+		}
+		// Successors with nodes of their own come first, and each target
+		// gets one edge: an empty then-branch would otherwise repeat the
+		// edge its if statement already draws to the code after it.
+		succs := slices.Clone(block.Succs)
+		slices.SortStableFunc(succs, func(a, b *cfg.Block) int {
+			return cmp.Compare(min(len(b.Nodes), 1), min(len(a.Nodes), 1))
+		})
+		drawn := make(map[string]bool)
+		edgeTo := func(target, attrs string) {
+			if drawn[target] {
+				return
+			}
+			drawn[target] = true
+			emit("  %s -> %s [%s];\n", lastNodeID, target, attrs)
+		}
+		// A range header ends in the loop block of its statement, which has
+		// no nodes; its branches into the body and past the loop are drawn
+		// from the header's last node.
+		if loop := rangeLoopOf(block); loop != nil && !ownEdges {
+			for _, succ := range loop.Succs {
+				target := fmt.Sprintf("block_%d", succ.Index)
+				if id := entryNodeID(cg, int(succ.Index)); id != "" {
+					target = id
+				}
+				color, weight := a.colors().branchColor(succ.Kind), a.probabilityAttrs(cg, loop, succ)
+				if outcome := a.branchOutcome(loop, succ); a.opts.BranchLabels && outcome != "" {
+					edgeTo(target, fmt.Sprintf("color=\"%s\" label=\"%s\"%s", color, escapeLabel(outcome), weight))
+				} else {
+					edgeTo(target, fmt.Sprintf("color=\"%s\" label=\"%s\" fontsize=14 decorate=true%s", color, succ.String(), weight))
+				}
+			}
+			ownEdges = true
+		}
+		for _, succ := range succs {
+			succID := fmt.Sprintf("block_%d", succ.Index)
+			//fmt.Printf("Block type: %s %d\n", succ.Kind, succ.Index) // debugging statement
+			weight := a.probabilityAttrs(cg, block, succ)
+			color := a.colors().branchColor(succ.Kind)
+			// The default clause of a switch is its else branch.
+			if entersDefault(succ) {
+				color = a.colors().otherwise
+			}
+
+			if lastNodeID == "" || ownEdges {
+				continue
+			}
+
+			// A block that is its own successor, such as the body of a bare
+			// "for { ... }", loops back to its first node.
+			if succ.Index == block.Index {
+				edgeTo(blockID+"_node_0", fmt.Sprintf("color=\"%s\" label=\"loop\"%s", color, weight))
+				continue
+			}
+
+			// A fallthrough enters the next case's body without testing
+			// its values.
+			if fallsThrough(block) {
+				target := succID
+				if id := entryNodeID(cg, int(succ.Index)); id != "" {
+					target = id
+				}
+				edgeTo(target, fmt.Sprintf("color=\"%s\" label=\"fallthrough\" style=bold%s", color, weight))
+				continue
+			}
+
+			// A branch consisting of a break, continue or goto jumps
+			// straight to its target, which for a labeled statement
+			// may be an outer loop; the edge is labeled with the jump.
+			if branch := branchVia(succ); branch != nil {
+				target := succID
+				if id := entryNodeID(cg, int(succ.Index)); id != "" {
+					target = id
+				}
+				// The target of a break or continue depends on the
+				// enclosing construct: a break in a switch leaves the
+				// switch, not the loop around it. One without a valid
+				// target does not compile and gets no edge.
+				if branch.Tok == token.BREAK || branch.Tok == token.CONTINUE {
+					stmt := branchTarget(fn, branch)
+					if stmt == nil {
+						continue
+					}
+					if id := branchTargetNode(cg, stmt, branch.Tok); id != "" {
+						target = id
+					}
+				}
+				label := branch.Tok.String()
+				if branch.Label != nil {
+					label += " " + branch.Label.Name
+				}
+				edgeTo(target, fmt.Sprintf("color=\"%s\" label=\"%s\"%s", color, label, weight))
+				continue
+			}
+
+			if entersDefault(succ) {
+				target := succID
+				if id := entryNodeID(cg, int(succ.Index)); id != "" {
+					target = id
+				}
+				edgeTo(target, fmt.Sprintf("color=\"%s\" label=\"default\"%s", color, weight))
+				continue
+			}
+
+			// With BranchLabels a branch edge is labeled with the
+			// outcome that takes it rather than the successor's block kind.
+			if outcome := a.branchOutcome(block, succ); a.opts.BranchLabels && outcome != "" {
+				target := succID
+				if id := entryNodeID(cg, int(succ.Index)); id != "" {
+					target = id
+				}
+				edgeTo(target, fmt.Sprintf("color=\"%s\" label=\"%s\"%s", color, escapeLabel(outcome), weight))
+				continue
+			}
+
+			// The edge is labeled with the kind of the successor, which
+			// go/cfg does not order within Succs, even when the successor
+			// has no nodes and the edge goes on to the next block that does.
+			target := succID
+			if id := entryNodeID(cg, int(succ.Index)); id != "" {
+				target = id
+			}
+			switch succ.Kind {
+			case cfg.KindIfThen, cfg.KindIfElse, cfg.KindIfDone, cfg.KindForBody, cfg.KindForDone, cfg.KindForLoop, cfg.KindForPost:
+				edgeTo(target, fmt.Sprintf("color=\"%s\" label=\"%s\" fontsize=14 decorate=true%s", color, succ.String(), weight))
+			default:
+				edgeTo(target, fmt.Sprintf("color=\"%s\"%s", color, weight))
+			}
+		}
+	}
+	if a.opts.MaxSelector > 0 {
+		emit("%s", a.selectorTooltips(cg))
+	}
+	if a.opts.DefUse && !a.opts.MetricsOnly {
+		for _, edge := range defUseEdges(cg, namer, info) {
+			emit("  %s -> %s [label=\"%s\" style=dotted fontsize=26];\n", edge.def, edge.use, edge.name)
+		}
+	} else {
+		for _, varName := range sortedKeys(variables) {
+			if nodes := variables[varName]; len(nodes) > 1 {
+				for i := 1; i < len(nodes); i++ {
+					emit("  %s -> %s [label=\"%s\" style=dotted fontsize=26];\n", nodes[i-1], nodes[i], varName)
+				}
+			}
+		}
+	}
+
+	// Determine unused variables
+	/*	for varName := range inputVars {
+		if !modifiedVars[varName] && controlVars[varName] == 0 {
+			unusedVars[varName] = true
+		}
+	} */
+
+	// The vocabulary counts every variable seen, including those that only
+	// appear in conditions and so have no data-flow node.
+	vocabulary := make(map[string]bool)
+	for varName := range variables {
+		vocabulary[varName] = true
+	}
+	for varName := range inputVars {
+		vocabulary[varName] = true
+	}
+	for varName := range modifiedVars {
+		vocabulary[varName] = true
+	}
+	for varName := range controlVars {
+		vocabulary[varName] = true
+	}
+
+	// Remove intersections between sets
+	for varName := range controlVars {
+		delete(inputVars, varName)
+		delete(modifiedVars, varName)
+	}
+	for varName := range modifiedVars {
+		delete(inputVars, varName)
+	}
+
+	for varName := range inputVars {
+		if inputVars[varName] == 1 {
+			unusedVars[varName] = true
+			delete(inputVars, varName)
+		}
+	}
+
+	chepin := &chepinSets{
+		input:        inputVars,
+		modified:     modifiedVars,
+		control:      controlVars,
+		unused:       unusedVars,
+		controlSites: controlSites,
+		vocabulary:   len(vocabulary),
+	}
+	if a.opts.MetricsOnly {
+		return "", chepin
+	}
+
+	re := regexp.MustCompile(`label="block \d+ ([^"]+)"`)
+	dot = re.ReplaceAllString(dot, `label="$1"`)
+	if a.opts.DefUseLabels {
+		dot = annotateDefsUses(dot, cg, namer, info)
+	}
+
+	dot += "}\n"
+
+	return dot, chepin
+}
+
+// executionOrder returns the live blocks of cg in reverse postorder of a
+// depth-first walk from the entry block, i.e. a topological order of the CFG
+// with loop back-edges ignored. Successors are visited in their CFG order so
+// the result is stable across runs.
+func executionOrder(cg *cfg.CFG) []*cfg.Block {
+	if len(cg.Blocks) == 0 {
+		return nil
+	}
+	visited := make(map[int32]bool)
+	var postorder []*cfg.Block
+	var visit func(block *cfg.Block)
+	visit = func(block *cfg.Block) {
+		visited[block.Index] = true
+		for _, succ := range block.Succs {
+			if !visited[succ.Index] {
+				visit(succ)
+			}
+		}
+		postorder = append(postorder, block)
+	}
+	visit(cg.Blocks[0])
+
+	order := []*cfg.Block{}
+	for i := len(postorder) - 1; i >= 0; i-- {
+		if postorder[i].Live {
+			order = append(order, postorder[i])
+		}
+	}
+	return order
+}
+
+// numberNodes prefixes every node label in dot with its ordinal in execution
+// order.
+func numberNodes(cg *cfg.CFG, dot string) string {
+	ordinals := make(map[string]int)
+	for _, block := range executionOrder(cg) {
+		for i := range block.Nodes {
+			ordinals[fmt.Sprintf("block_%d_node_%d", block.Index, i)] = len(ordinals) + 1
+		}
+	}
+	re := regexp.MustCompile(`(?m)^  (block_\d+_node_\d+) \[label="`)
+	return re.ReplaceAllStringFunc(dot, func(decl string) string {
+		nodeID := re.FindStringSubmatch(decl)[1]
+		if ordinal, ok := ordinals[nodeID]; ok {
+			return fmt.Sprintf("%s%d: ", decl, ordinal)
+		}
+		return decl
+	})
+}
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// cyclomatic computes the cyclomatic complexity of cg over its live blocks
+// and returns it together with the edge and node counts.
+func cyclomatic(cg *cfg.CFG) (complexity, numEdges, numNodes int) {
+	var counter cyclomaticCounter
+	walkCFG(cg, counter.visit)
+	return counter.complexity(), counter.edges, counter.nodes
+}
+
+// cyclomaticCounter counts the live blocks, their edges and the exit blocks
+// of a CFG as a NodeVisitor.
+type cyclomaticCounter struct {
+	edges, nodes, exits int
+}
+
+func (c *cyclomaticCounter) visit(node ast.Node, block *cfg.Block) {
+	if node != nil {
+		return
+	}
+	c.nodes++
+	c.edges += len(block.Succs)
+	if len(block.Succs) == 0 {
+		c.exits++
+	}
+}
+
+// complexity returns the cyclomatic complexity of the blocks visited so far.
+// E - N + 2 assumes a single exit, so the exit blocks are first joined to a
+// virtual one, which adds an edge per exit and a node: E - N + 1 + exits.
+func (c *cyclomaticCounter) complexity() int {
+	return c.edges - c.nodes + 1 + max(c.exits, 1)
+}
+
+// succOfKind returns the successor of block with the given kind, or nil.
+// go/cfg does not promise any particular order of Succs, so branch targets
+// are always looked up by kind.
+func succOfKind(block *cfg.Block, kind cfg.BlockKind) *cfg.Block {
+	for _, succ := range block.Succs {
+		if succ.Kind == kind {
+			return succ
+		}
+	}
+	return nil
+}
+
+// branchOutcome returns the outcome of the condition ending block that leads
+// to succ: "true" or "false" for if and for conditions, the case values (or
+// "no match") for switch cases, "next" or "done" for range loops. It returns
+// "" if block does not branch.
+func (a *analyzer) branchOutcome(block, succ *cfg.Block) string {
+	if len(block.Succs) < 2 {
+		return ""
+	}
+	switch succ.Kind {
+	case cfg.KindIfThen, cfg.KindForBody:
+		return "true"
+	case cfg.KindIfElse, cfg.KindIfDone, cfg.KindForDone:
+		return "false"
+	case cfg.KindRangeBody:
+		return "next"
+	case cfg.KindRangeDone:
+		return "done"
+	case cfg.KindSwitchCaseBody:
+		if clause, ok := succ.Stmt.(*ast.CaseClause); ok {
+			if len(clause.List) == 0 {
+				return "default"
+			}
+			values := []string{}
+			for _, value := range clause.List {
+				values = append(values, a.getValue(value))
+			}
+			return "case " + strings.Join(values, ", ")
+		}
+	case cfg.KindSwitchNextCase, cfg.KindSwitchDone:
+		return "no match"
+	}
+	return ""
+}
+
+// entryNodeID returns the ID of the first node reached on entering the block
+// with the given index, or "" if there is none. Entering the loop block of
+// a range statement, as every iteration does, leads back to its header
+// rather than on into the body.
+func entryNodeID(cg *cfg.CFG, index int) string {
+	visited := map[int]bool{index: true}
+	queue := []int{index}
+	for len(queue) > 0 {
+		block := cg.Blocks[queue[0]]
+		queue = queue[1:]
+		if block.Kind == cfg.KindRangeLoop {
+			if id := rangeHeadID(cg, block); id != "" {
+				return id
+			}
+		}
+		if len(block.Nodes) > 0 {
+			return fmt.Sprintf("block_%d_node_0", block.Index)
+		}
+		for _, succ := range block.Succs {
+			if !visited[int(succ.Index)] {
+				visited[int(succ.Index)] = true
+				queue = append(queue, int(succ.Index))
+			}
+		}
+	}
+	return ""
+}
+
+func findNextBlockWithNodes(cg *cfg.CFG, startIndex int) *cfg.Block {
+	visited := map[int]bool{startIndex: true}
+	queue := []int{startIndex}
+
+	for len(queue) > 0 {
+		currentIndex := queue[0]
+		queue = queue[1:]
+
+		currentBlock := cg.Blocks[currentIndex]
+		if len(currentBlock.Nodes) > 0 {
+			return currentBlock
+		}
+
+		for _, succ := range currentBlock.Succs {
+			if !visited[int(succ.Index)] {
+				visited[int(succ.Index)] = true
+				queue = append(queue, int(succ.Index))
+			}
+		}
+	}
+
+	return nil
+}
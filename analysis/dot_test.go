@@ -0,0 +1,452 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// parseSnippet parses body wrapped in a function, as with -e, and returns
+// the file and the function.
+func parseSnippet(t *testing.T, body string) (*token.FileSet, *ast.File, *ast.FuncDecl) {
+	t.Helper()
+	return parseSource(t, SnippetSource(body))
+}
+
+// parseSource parses src and returns its file and first function.
+func parseSource(t *testing.T, src string) (*token.FileSet, *ast.File, *ast.FuncDecl) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", src, err)
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			return fset, file, fn
+		}
+	}
+	t.Fatalf("no function in %q", src)
+	return nil, nil, nil
+}
+
+// newCFG builds the CFG of fn as analyzeFunc does.
+func newCFG(fn *ast.FuncDecl) *cfg.CFG {
+	return cfg.New(fn.Body, func(*ast.CallExpr) bool { return true })
+}
+
+// defaultAnalyzer returns an analyzer with DefaultOptions.
+func defaultAnalyzer() *analyzer {
+	return &analyzer{opts: DefaultOptions()}
+}
+
+// snippetDot returns the DOT graph genDot draws for body with opts.
+func snippetDot(t *testing.T, opts Options, body string) string {
+	t.Helper()
+	fset, _, fn := parseSnippet(t, body)
+	a := &analyzer{opts: opts}
+	dot, _ := a.genDot(fset, fn, newCFG(fn), nil)
+	return dot
+}
+
+// dotEdge is a control-flow edge of a DOT graph, between node labels.
+type dotEdge struct {
+	from, to, attrs string
+}
+
+var dotEdgeLine = regexp.MustCompile(`(?m)^  (block_\d+_node_\d+) -> (block_\d+_node_\d+)(?: \[(.*)\])?;$`)
+
+// dotEdges returns the edges of dot by node label, leaving out the dotted
+// data-flow edges.
+func dotEdges(dot string) []dotEdge {
+	labels := dotLabels(dot)
+	var edges []dotEdge
+	for _, m := range dotEdgeLine.FindAllStringSubmatch(dot, -1) {
+		if regexp.MustCompile(`style=dotted`).MatchString(m[3]) {
+			continue
+		}
+		edges = append(edges, dotEdge{labels[m[1]], labels[m[2]], m[3]})
+	}
+	return edges
+}
+
+// dataEdges returns the dotted data-flow edges of dot by node label, with
+// the variable each carries in attrs.
+func dataEdges(dot string) []dotEdge {
+	labels := dotLabels(dot)
+	var edges []dotEdge
+	for _, m := range dotEdgeLine.FindAllStringSubmatch(dot, -1) {
+		if name := dataEdgeName.FindStringSubmatch(m[3]); name != nil {
+			edges = append(edges, dotEdge{labels[m[1]], labels[m[2]], name[1]})
+		}
+	}
+	return edges
+}
+
+var dataEdgeName = regexp.MustCompile(`^label="([^"]*)" style=dotted`)
+
+// edgesFrom returns the edges of dot leaving the node labeled from.
+func edgesFrom(dot, from string) []dotEdge {
+	var edges []dotEdge
+	for _, edge := range dotEdges(dot) {
+		if edge.from == from {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// hasLabel reports whether a node of dot is labeled label.
+func hasLabel(dot, label string) bool {
+	for _, l := range dotLabels(dot) {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// firstOf returns the first node of type T in root, in source order.
+func firstOf[T ast.Node](t *testing.T, root ast.Node) T {
+	t.Helper()
+	var found T
+	var ok bool
+	ast.Inspect(root, func(n ast.Node) bool {
+		if !ok {
+			found, ok = n.(T)
+		}
+		return !ok
+	})
+	if !ok {
+		t.Fatalf("no %T in the source", found)
+	}
+	return found
+}
+
+func TestForLabel(t *testing.T) {
+	tests := []struct{ body, want string }{
+		{"for {\n}", "for"},
+		{"for i < 3 {\n}", "for i < 3"},
+		{"for i := 0; i < 3; i++ {\n}", "for i := 0; i < 3; i++"},
+		{"for i := 0; i < 3; i += 2 {\n}", "for i := 0; i < 3; i += 2"},
+		{"for ; i < 3; i++ {\n}", "for ; i < 3; i++"},
+		{"for i := 0; ; {\n}", "for i := 0; ; "},
+	}
+	for _, test := range tests {
+		_, _, fn := parseSnippet(t, test.body)
+		if got := defaultAnalyzer().forLabel(firstOf[*ast.ForStmt](t, fn)); got != test.want {
+			t.Errorf("forLabel(%q) = %q, want %q", test.body, got, test.want)
+		}
+	}
+}
+
+func TestMultiValueAssignment(t *testing.T) {
+	dot := snippetDot(t, DefaultOptions(), "a, b := f()\na = 1\nb = 2")
+	if !hasLabel(dot, "a, b = f()") {
+		t.Errorf("no node labeled %q in\n%s", "a, b = f()", dot)
+	}
+	want := []dotEdge{{"a, b = f()", "a = 1", "a"}, {"a, b = f()", "b = 2", "b"}}
+	if got := dataEdges(dot); !slices.Equal(got, want) {
+		t.Errorf("data-flow edges %v, want %v", got, want)
+	}
+}
+
+// TestNodeLabels checks the label genDot gives the node of a statement.
+func TestNodeLabels(t *testing.T) {
+	tests := []struct{ name, body, want string }{
+		{"receive statement", "<-ch", "<-ch"},
+		{"selector statement", "x.y", "x.y"},
+		{"unary statement", "-x", "-x"},
+		{"dereference statement", "*p", "*p"},
+		{"parenthesized statement", "(f())", "(f())"},
+		{"slice type", "x := make([]int, n)", "x = make([]int, n)"},
+		{"map type", "m := make(map[string][]int)", "m = make(map[string][]int)"},
+		{"send channel type", "c := make(chan<- int, 1)", "c = make(chan<- int, 1)"},
+		{"receive channel type", "c := make(<-chan bool)", "c = make(<-chan bool)"},
+		{"array type", "var a [3]int", "a [3]int"},
+		{"index", "z := s[i]", "z = s[i]"},
+		{"generic type", "y := Map[string, int]{}", "y = Map[string, int]{}"},
+		{"generic call", "w := f[int](3)", "w = f[int](3)"},
+		{"var with shared type", "var a, b, c int", "a, b, c int"},
+		{"var with values", "var x, y = 1, 2", "x, y = 1, 2"},
+		{"grouped var", "var (\n\tp, q string\n\tr    = 3\n)", "p, q string"},
+		{"typed var with value", "var s []byte = nil", "s []byte = nil"},
+		{"slice", "t := s[i:j]", "t = s[i:j]"},
+		{"open slice", "t := s[:]", "t = s[:]"},
+		{"full slice", "t := s[i:j:k]", "t = s[i:j:k]"},
+		{"index increment", "a[i]++", "a[i] ++"},
+		{"call of call result", "f(1)(2)", "f(1)(2)"},
+		{"function literal", "h := func(a, b int, s string) (int, error) { return 0, nil }", "h = func(a, b int, s string) (int, error) {…}"},
+		{"function type", "var g func(int) func() bool", "g func(int) func() bool"},
+		{"function argument", "k := compose(func(x int) int { return x }, h)", "k = compose(func(x int) int {…}, h)"},
+		{"array length expression", "var buf [n + 1]byte", "buf [n + 1]byte"},
+		{"array of arrays", "var m [2][3]float64", "m [2][3]float64"},
+		{"implicit array length", "b := [...]int{1, 2}", "b = [...]int{1, 2}"},
+		{"composite literal receiver", "T{A: 1}.Run()", "T{A: 1}.Run()"},
+		{"composite literal address", "p := &Point{X: 1, Y: 2}", "p = &Point{X: 1, Y: 2}"},
+		{"indexed composite literal", "[]int{1, 2}[0]++", "[]int{1, 2}[0] ++"},
+		{"anonymous struct", "s := struct{ a int }{1}", "s = struct{…}{1}"},
+		{"empty struct", "e := struct{}{}", "e = struct{}{}"},
+		{"bare condition", "if ok {\n\tx()\n}", "ok"},
+		{"negated condition", "if !ok {\n\tx()\n}", "!ok"},
+		{"bare loop condition", "for ok {\n\tx()\n}", "for ok"},
+		{"switch tag", "switch m[k] {\ncase 1:\n\tx()\n}", "m[k]"},
+		{"case value", "switch m[k] {\ncase 1:\n\tx()\n}", "1"},
+		{"imaginary literal", "z := 3i", "z = 3i"},
+		{"float literal", "f := 1e-3", "f = 1e-3"},
+		{"rune literal", `r := '\''`, `r = '\''`},
+		{"string with escapes", `s := "a\t\"b\""`, `s = "a\t\"b\""`},
+		{"raw string", "s := `C:\\dir`", "s = `C:\\dir`"},
+		{"literal condition", "if c == 'x' {\n\ty()\n}", "c == 'x'"},
+		{"spread call", "f(xs...)", "f(xs...)"},
+		{"spread of a call result", "x := append(a, g()...)", "x = append(a, g()...)"},
+		{"spread of a slice expression", "x := append(a, b[1:]...)", "x = append(a, b[1:]...)"},
+		{"variadic literal", "h := func(format string, args ...any) {}", "h = func(format string, args ...any) {…}"},
+		{"variadic function type", "var g func(...int) int", "g func(...int) int"},
+		{"parenthesized condition", "if (a > b) {\n\tx()\n}", "(a > b)"},
+		{"parenthesized call condition", "if (ok()) {\n\tx()\n}", "(ok())"},
+		{"parenthesized loop condition", "for (i < n) {\n\tx()\n}", "for (i < n)"},
+		{"parenthesized case value", "switch {\ncase (a > b):\n\tx()\n}", "(a > b)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if dot := snippetDot(t, DefaultOptions(), test.body); !hasLabel(dot, test.want) {
+				t.Errorf("no node labeled %q in\n%s", test.want, dot)
+			}
+		})
+	}
+}
+
+// TestGetValuePrecedence renders ASTs built by hand, whose grouping has no
+// parentheses to keep.
+func TestGetValuePrecedence(t *testing.T) {
+	bin := func(x ast.Expr, op token.Token, y ast.Expr) ast.Expr {
+		return &ast.BinaryExpr{X: x, Op: op, Y: y}
+	}
+	a, b, c := ast.NewIdent("a"), ast.NewIdent("b"), ast.NewIdent("c")
+	tests := []struct {
+		expr ast.Expr
+		want string
+	}{
+		{bin(bin(a, token.ADD, b), token.MUL, c), "(a + b) * c"},
+		{bin(a, token.ADD, bin(b, token.MUL, c)), "a + b * c"},
+		{bin(bin(a, token.SUB, b), token.SUB, c), "a - b - c"},
+		{bin(a, token.SUB, bin(b, token.SUB, c)), "a - (b - c)"},
+		{bin(bin(a, token.LOR, b), token.LAND, c), "(a || b) && c"},
+	}
+	for _, test := range tests {
+		if got := getValue(test.expr); got != test.want {
+			t.Errorf("getValue = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestNakedReturn(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"named results", "func f() (n int, err error) {\n\tn = 1\n\treturn\n}", "Return: (n, err)"},
+		{"blank result", "func f() (_ int, err error) {\n\treturn\n}", "Return: (err)"},
+		{"explicit values", "func f() (n int) {\n\treturn 2\n}", "Return: 2"},
+		{"no results", "func f() {\n\treturn\n}", "Return: "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSource(t, "package p\n\n"+test.src+"\n")
+			dot, _ := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil)
+			if !hasLabel(dot, test.want) {
+				t.Errorf("no node labeled %q in\n%s", test.want, dot)
+			}
+		})
+	}
+
+	fset, _, fn := parseSource(t, "package p\n\nfunc f() (n int) {\n\tn = 1\n\treturn\n}\n")
+	dot, _ := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil)
+	want := []dotEdge{{"n = 1", "Return: (n)", "n"}}
+	if got := dataEdges(dot); !slices.Equal(got, want) {
+		t.Errorf("data-flow edges %v, want %v", got, want)
+	}
+}
+
+func TestCondVars(t *testing.T) {
+	tests := []struct {
+		cond string
+		want []string
+	}{
+		{"a > 0 && (b < c || f(d))", []string{"a", "b", "c", "d"}},
+		{"!done && x != nil", []string{"done", "x"}},
+		{"s.ok || m[k] == true", []string{"s", "m", "k"}},
+		{"p.f(q) && g()", []string{"p", "q"}},
+		{"func() bool { return z }()", nil},
+	}
+	for _, test := range tests {
+		_, _, fn := parseSnippet(t, "if "+test.cond+" {\n}")
+		cond := firstOf[*ast.IfStmt](t, fn).Cond
+		if got := condVars(newVarNamer(nil), cond); !slices.Equal(got, test.want) {
+			t.Errorf("condVars(%s) = %v, want %v", test.cond, got, test.want)
+		}
+	}
+
+	fset, _, fn := parseSnippet(t, "if a > 0 && (b < c || a < 9) {\n}")
+	_, sets := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil)
+	for _, name := range []string{"a", "b", "c"} {
+		if sets.control[name] != 1 {
+			t.Errorf("%s controls %d decisions, want 1", name, sets.control[name])
+		}
+	}
+}
+
+func TestIndexUses(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want []string
+	}{
+		{"t := s[i:j]", []string{"s", "i", "j"}},
+		{"t := s[i:j:k]", []string{"s", "i", "j", "k"}},
+		{"x := m[k] + m[k]", []string{"m", "k"}},
+		{"x := a[b[i]]", []string{"a", "b", "i"}},
+		{"f := func() int { return a[i] }", nil},
+		{"var buf [n]byte", []string{"n"}},
+		{"b := make([]int, k)", nil},
+		{"var m [rows][cols + 1]int", []string{"rows", "cols"}},
+	}
+	for _, test := range tests {
+		_, _, fn := parseSnippet(t, test.stmt)
+		if got := indexUses(newVarNamer(nil), fn.Body.List[0]); !slices.Equal(got, test.want) {
+			t.Errorf("indexUses(%s) = %v, want %v", test.stmt, got, test.want)
+		}
+	}
+}
+
+func TestBlankIdentifier(t *testing.T) {
+	fset, _, fn := parseSnippet(t, "_, err := f()\n_ = err\nvar _ = 3\n_, err = g()\nfor _, v := range s {\n\t_ = v\n}")
+	dot, sets := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil)
+	if !hasLabel(dot, "_, err = f()") {
+		t.Errorf("no node labeled %q in\n%s", "_, err = f()", dot)
+	}
+	if _, ok := sets.input["_"]; ok {
+		t.Errorf("_ is an input variable: %v", sets.input)
+	}
+	if sets.modified["_"] {
+		t.Errorf("_ is a modified variable: %v", sets.modified)
+	}
+	for _, edge := range dataEdges(dot) {
+		if edge.attrs == "_" {
+			t.Errorf("data-flow edge for _: %v", edge)
+		}
+	}
+	_, _, fn = parseSnippet(t, "_, x = 1, 2")
+	if got := modifiedBy(fn.Body.List[0]); len(got) != 1 || got[0].Name != "x" {
+		t.Errorf("modifiedBy = %v, want [x]", got)
+	}
+}
+
+func TestSelfAssignmentModifies(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       bool // whether x is modified
+	}{
+		{"constant", "x := 0\nx = 1", false},
+		{"other variable", "x := 0\nx = y", false},
+		{"conversion", "x := 0\nx = int64(x)", true},
+		{"call", "x := 0\nx = f(x)", true},
+		{"compound", "x := 0\nx += 1", true},
+		{"binary", "x := y + 1", true},
+		{"field of the same name", "x := 0\nx = p.x", false},
+		{"method on x", "x := 0\nx = x.Next()", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			if _, sets := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil); sets.modified["x"] != test.want {
+				t.Errorf("x modified = %v, want %v", sets.modified["x"], test.want)
+			}
+		})
+	}
+}
+
+func TestFuncName(t *testing.T) {
+	tests := []struct {
+		decl, want string
+	}{
+		{"func F() {}", "F"},
+		{"func (t T) M() {}", "T.M"},
+		{"func (t *T) M() {}", "(*T).M"},
+		{"func (*T) M() {}", "(*T).M"},
+		{"func (t *T[K]) M() {}", "(*T[K]).M"},
+		{"func (t (*T)) M() {}", "(*T).M"},
+	}
+	for _, test := range tests {
+		_, _, fn := parseSource(t, "package p\n\n"+test.decl+"\n")
+		if got := funcName(fn); got != test.want {
+			t.Errorf("funcName(%s) = %q, want %q", test.decl, got, test.want)
+		}
+	}
+}
+
+func TestChannelUses(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want []string
+	}{
+		{"ch <- x", []string{"ch", "x"}},
+		{"ch <- x + y", []string{"ch", "x", "y"}},
+		{"v := <-ch", []string{"ch"}},
+		{"v, ok := <-ch", []string{"ch"}},
+		{"ch <- <-in", []string{"ch", "in"}},
+		{"f(<-a, <-a)", []string{"a"}},
+		{"g := func() { ch <- x }", nil},
+		{"x := -y", nil},
+	}
+	for _, test := range tests {
+		_, _, fn := parseSnippet(t, test.stmt)
+		if got := channelUses(newVarNamer(nil), fn.Body.List[0]); !slices.Equal(got, test.want) {
+			t.Errorf("channelUses(%s) = %v, want %v", test.stmt, got, test.want)
+		}
+	}
+	if dot := snippetDot(t, DefaultOptions(), "ch <- x"); !hasLabel(dot, "ch <- x") {
+		t.Errorf("send not labeled \"ch <- x\":\n%s", dot)
+	}
+}
+
+func TestElementTargets(t *testing.T) {
+	tests := []struct {
+		name, body, label string
+		modified          []string
+	}{
+		{"index", "a[i] = v", "a[i] = v", []string{"a"}},
+		{"field", "p.f = v", "p.f = v", []string{"p"}},
+		{"pointee", "*p = v", "*p = v", []string{"p"}},
+		{"nested", "m[k].s[j] = v", "m[k].s[j] = v", []string{"m"}},
+		{"mixed", "x, a[i] = f()", "x, a[i] = f()", []string{"a"}},
+		{"call result", "f().x = v", "f().x = v", nil},
+		{"range key and element", "for k, m[k] = range xs {\n}", "m[k] = value of xs", []string{"k", "m"}},
+		{"range discards", "for _, _ = range xs {\n}", "value of xs discarded", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			dot, sets := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil)
+			if !hasLabel(dot, test.label) {
+				t.Errorf("no node labeled %q:\n%s", test.label, dot)
+			}
+			if got := sortedKeys(sets.modified); !slices.Equal(got, test.modified) {
+				t.Errorf("modified %v, want %v", got, test.modified)
+			}
+		})
+	}
+	for _, expr := range []string{"_", "f()", "f().x"} {
+		e, err := parser.ParseExpr(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if base := targetBase(e); base != nil {
+			t.Errorf("targetBase(%s) = %s, want nil", expr, base.Name)
+		}
+	}
+}
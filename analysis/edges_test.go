@@ -0,0 +1,226 @@
+package analysis
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestBranchEdgesBySuccessorKind reverses the successors of every block, so
+// code taking Succs[0] as the then or body branch labels the edges wrongly.
+func TestBranchEdgesBySuccessorKind(t *testing.T) {
+	tests := []struct {
+		name, body, cond string
+		want             map[string]string // target label -> expected attributes
+	}{
+		{"if else", "if a > b {\n\tx()\n} else {\n\tz()\n}", "a > b", map[string]string{
+			"x()": `color="yellow" label="(IfThen)"`,
+			"z()": `color="red" label="(IfElse)"`,
+		}},
+		{"for", "for i < 3 {\n\tx()\n}\nz()", "for i < 3", map[string]string{
+			"x()": `color="yellow" label="(ForBody)"`,
+			"z()": `color="red" label="(ForDone)"`,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			cg := newCFG(fn)
+			for _, block := range cg.Blocks {
+				slices.Reverse(block.Succs)
+			}
+			dot, _ := defaultAnalyzer().genDot(fset, fn, cg, nil)
+			edges := edgesFrom(dot, test.cond)
+			if len(edges) != len(test.want) {
+				t.Fatalf("%d edges leave %q, want %d:\n%s", len(edges), test.cond, len(test.want), dot)
+			}
+			for _, edge := range edges {
+				if want := test.want[edge.to]; !strings.HasPrefix(edge.attrs, want) {
+					t.Errorf("edge to %q has [%s], want [%s ...]", edge.to, edge.attrs, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBranchEdgeCount(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       map[string]int // node label -> outgoing edges
+	}{
+		{"if else", "if a > b {\n\tx()\n} else {\n\tz()\n}\ny()", map[string]int{"a > b": 2, "x()": 1, "z()": 1}},
+		{"if without else", "if a > b {\n\tx()\n}\ny()", map[string]int{"a > b": 2, "x()": 1}},
+		{"empty then", "if a > b {\n}\ny()", map[string]int{"a > b": 1}},
+		{"empty then and else", "if a > b {\n} else {\n}\ny()", map[string]int{"a > b": 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dot := snippetDot(t, DefaultOptions(), test.body)
+			for from, want := range test.want {
+				if got := len(edgesFrom(dot, from)); got != want {
+					t.Errorf("%d edges leave %q, want %d:\n%s", got, from, want, dot)
+				}
+			}
+		})
+	}
+}
+
+func TestSelfLoop(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       dotEdge
+	}{
+		{"one node", "for {\n\tx()\n}", dotEdge{"x()", "x()", `label="loop"`}},
+		{"two nodes", "for {\n\tx()\n\ty()\n}", dotEdge{"y()", "x()", `label="loop"`}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dot := snippetDot(t, DefaultOptions(), test.body)
+			edges := edgesFrom(dot, test.want.from)
+			if len(edges) != 1 || edges[0].to != test.want.to || !strings.Contains(edges[0].attrs, test.want.attrs) {
+				t.Errorf("edges from %q are %v, want one %v", test.want.from, edges, test.want)
+			}
+		})
+	}
+}
+
+// TestBlockOrder checks that the DOT output does not depend on map
+// iteration order, which varies between runs, and that blocks are emitted
+// by index.
+func TestBlockOrder(t *testing.T) {
+	body := "a := 0\nb := 1\nfor i := 0; i < 3; i++ {\n\tif a > b {\n\t\ta = b\n\t} else {\n\t\tb = a\n\t}\n}\nprintln(a, b)"
+	want := snippetDot(t, DefaultOptions(), body)
+	for range 20 {
+		if got := snippetDot(t, DefaultOptions(), body); got != want {
+			t.Fatalf("output differs:\n%s\nwant:\n%s", got, want)
+		}
+	}
+	var indices []int
+	for _, m := range regexp.MustCompile(`(?m)^  block_(\d+)_node_0 \[`).FindAllStringSubmatch(want, -1) {
+		index, _ := strconv.Atoi(m[1])
+		indices = append(indices, index)
+	}
+	if !slices.IsSorted(indices) {
+		t.Errorf("blocks emitted in order %v", indices)
+	}
+	_, _, fn := parseSnippet(t, body)
+	for i, block := range newCFG(fn).Blocks {
+		if int(block.Index) != i {
+			t.Errorf("block %d at position %d of Blocks", block.Index, i)
+		}
+	}
+}
+
+func TestBranchLabels(t *testing.T) {
+	opts := DefaultOptions()
+	opts.BranchLabels = true
+	tests := []struct {
+		name, body, from string
+		want             map[string]string // target label -> edge label
+	}{
+		{"if else", "if a > b {\n\tx()\n} else {\n\ty()\n}", "a > b", map[string]string{"x()": "true", "y()": "false"}},
+		{"if", "if a > b {\n\tx()\n}\nz()", "a > b", map[string]string{"x()": "true", "z()": "false"}},
+		{"for", "for i < 3 {\n\tx()\n}\nz()", "for i < 3", map[string]string{"x()": "true", "z()": "false"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dot := snippetDot(t, opts, test.body)
+			got := make(map[string]string)
+			for _, edge := range edgesFrom(dot, test.from) {
+				if m := regexp.MustCompile(`label="([^"]*)"`).FindStringSubmatch(edge.attrs); m != nil {
+					got[edge.to] = m[1]
+				}
+			}
+			if !maps.Equal(got, test.want) {
+				t.Errorf("edge labels %v, want %v\n%s", got, test.want, dot)
+			}
+		})
+	}
+}
+
+func TestBranchOutcome(t *testing.T) {
+	_, _, fn := parseSnippet(t, "switch a {\ncase 1, 2:\n\tx()\ndefault:\n\ty()\n}\nfor range s {\n}")
+	cg := newCFG(fn)
+	got := make(map[string]bool)
+	for _, block := range cg.Blocks {
+		for _, succ := range block.Succs {
+			if outcome := defaultAnalyzer().branchOutcome(block, succ); outcome != "" {
+				got[outcome] = true
+			}
+		}
+	}
+	for _, want := range []string{"case 1, 2", "no match", "next", "done"} {
+		if !got[want] {
+			t.Errorf("no %q outcome among %v", want, got)
+		}
+	}
+}
+
+func TestFallthroughEdges(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       map[string]string // fallthrough edges, from -> to
+	}{
+		{"fallthrough", "switch {\ncase x > 1:\n\ta()\n\tfallthrough\ncase x > 0:\n\tb()\n}\nc()",
+			map[string]string{"a()": "b()"}},
+		{"into default", "switch {\ncase x > 1:\n\ta()\n\tfallthrough\ndefault:\n\tb()\n}",
+			map[string]string{"a()": "b()"}},
+		{"chain", "switch {\ncase x > 2:\n\ta()\n\tfallthrough\ncase x > 1:\n\tb()\n\tfallthrough\ncase x > 0:\n\tc()\n}",
+			map[string]string{"a()": "b()", "b()": "c()"}},
+		{"none", "switch {\ncase x > 1:\n\ta()\ncase x > 0:\n\tb()\n}", map[string]string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dot := snippetDot(t, DefaultOptions(), test.body)
+			got := make(map[string]string)
+			for _, edge := range dotEdges(dot) {
+				if strings.Contains(edge.attrs, `label="fallthrough"`) {
+					got[edge.from] = edge.to
+				}
+			}
+			if !maps.Equal(got, test.want) {
+				t.Errorf("fallthrough edges %v, want %v:\n%s", got, test.want, dot)
+			}
+			for from := range test.want {
+				if edges := edgesFrom(dot, from); len(edges) != 1 {
+					t.Errorf("%d edges leave %q, want only the fallthrough edge", len(edges), from)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultEdges(t *testing.T) {
+	tests := []struct {
+		name, body, from, to string // the default edge, none if from is ""
+	}{
+		{"tag switch", "switch x {\ncase 1:\n\ta()\ncase 2:\n\tb()\ndefault:\n\tc()\n}\nd()", "2", "c()"},
+		{"default first", "switch x {\ndefault:\n\tc()\ncase 1:\n\ta()\n}\nd()", "1", "c()"},
+		{"empty default", "switch {\ncase x > 1:\n\ta()\ndefault:\n}\nd()", "x > 1", "d()"},
+		{"no default", "switch x {\ncase 1:\n\ta()\n}\nd()", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dot := snippetDot(t, DefaultOptions(), test.body)
+			var defaults []dotEdge
+			for _, edge := range dotEdges(dot) {
+				if strings.Contains(edge.attrs, `label="default"`) {
+					defaults = append(defaults, edge)
+				}
+			}
+			if test.from == "" {
+				if len(defaults) > 0 {
+					t.Errorf("default edges %v in a switch without default:\n%s", defaults, dot)
+				}
+				return
+			}
+			want := dotEdge{test.from, test.to, `color="red" label="default"`}
+			if len(defaults) != 1 || defaults[0] != want {
+				t.Errorf("default edges %v, want [%v]:\n%s", defaults, want, dot)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// FileMetrics treats a file as a single unit: the totals of the metrics of
+// its analyzed functions and the number of its package-level declarations.
+// Options.OnFile receives one per file.
+type FileMetrics struct {
+	Kind           string `json:"kind"` // always "file"
+	File           string `json:"file"`
+	Lines          int    `json:"lines"`
+	Functions      int    `json:"functions"`
+	DecisionPoints int    `json:"decision_points"`
+	Statements     int    `json:"statements"`
+	Variables      int    `json:"variables"`
+	Imports        int    `json:"imports"`
+	Consts         int    `json:"consts"`
+	Vars           int    `json:"vars"`
+	Types          int    `json:"types"`
+	Funcs          int    `json:"funcs"` // functions and methods, analyzed or not
+}
+
+// summarizeFile sums the metrics of results, the analyzed functions of
+// file, and counts the declarations of file. The decision points of a
+// function are those counted by its AST cyclomatic complexity, so they are
+// 0 when Options.Metrics leaves out cyclomatic.
+func summarizeFile(fset *token.FileSet, file *ast.File, results []*Result) FileMetrics {
+	m := FileMetrics{
+		Kind:      "file",
+		File:      fset.Position(file.Package).Filename,
+		Lines:     fset.File(file.Pos()).LineCount(),
+		Functions: len(results),
+	}
+	for _, result := range results {
+		m.DecisionPoints += max(result.Metrics.ASTCyclomatic-1, 0)
+		m.Statements += result.Metrics.Statements
+		m.Variables += result.Metrics.Vocabulary
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			m.Funcs++
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.ImportSpec:
+					m.Imports++
+				case *ast.TypeSpec:
+					m.Types++
+				case *ast.ValueSpec:
+					if decl.Tok == token.CONST {
+						m.Consts += len(spec.Names)
+					} else {
+						m.Vars += len(spec.Names)
+					}
+				}
+			}
+		}
+	}
+	return m
+}
+
+// recordFile passes the file metrics of file to Options.OnFile and writes
+// them to the text report.
+func (a *analyzer) recordFile(fset *token.FileSet, file *ast.File, results []*Result) {
+	m := summarizeFile(fset, file, results)
+	a.opts.OnFile(m)
+	a.textf("%s\n", strings.Repeat("-", 18))
+	a.textf("File: %s (%d lines)\n", m.File, m.Lines)
+	a.textf("  Functions analyzed: %d\n", m.Functions)
+	a.textf("  Decision points: %d\n", m.DecisionPoints)
+	a.textf("  Statements: %d\n", m.Statements)
+	a.textf("  Variables: %d\n", m.Variables)
+	a.textf("  Declarations: %d imports, %d consts, %d vars, %d types, %d funcs\n", m.Imports, m.Consts, m.Vars, m.Types, m.Funcs)
+}
@@ -0,0 +1,51 @@
+package analysis
+
+import "testing"
+
+func TestSummarizeFile(t *testing.T) {
+	tests := []struct {
+		name, src string
+		results   []*Result
+		want      FileMetrics
+	}{
+		{"nothing analyzed", "package p\n\nfunc f() {}\n", nil, FileMetrics{Kind: "file", File: "test.go", Lines: 3, Funcs: 1}},
+		{"declarations", `package p
+
+import (
+	"fmt"
+	"os"
+)
+
+const a, b = 1, 2
+
+var (
+	x int
+	y, z = 3, 4
+)
+
+type T struct{}
+
+func (T) M() {}
+
+func f() {
+	fmt.Println(os.Args)
+}
+`, []*Result{
+			{Metrics: Metrics{ASTCyclomatic: 1, Statements: 0, Vocabulary: 0}},
+			{Metrics: Metrics{ASTCyclomatic: 3, Statements: 5, Vocabulary: 2}},
+			{Metrics: Metrics{ASTCyclomatic: 0, Statements: 1, Vocabulary: 1}}, // cyclomatic left out by -metrics
+		}, FileMetrics{
+			Kind: "file", File: "test.go", Lines: 21, Functions: 3,
+			DecisionPoints: 2, Statements: 6, Variables: 3,
+			Imports: 2, Consts: 2, Vars: 3, Types: 1, Funcs: 2,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, file, _ := parseSource(t, test.src)
+			if got := summarizeFile(fset, file, test.results); got != test.want {
+				t.Errorf("summarizeFile =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
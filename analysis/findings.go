@@ -0,0 +1,284 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// Finding is a diagnostic reported for an analyzed function. Severity is one
+// of the SARIF levels "error", "warning" or "note". Suppressed findings were
+// silenced by an ignore directive; they are counted but not reported as
+// failures.
+type Finding struct {
+	RuleID     string
+	Message    string
+	Severity   string
+	Pos        token.Position
+	End        token.Position
+	Suppressed bool
+}
+
+// ignoreDirective is the comment prefix that suppresses findings for the
+// function it is attached to, e.g. "//cfg:ignore complexity". Without rule
+// names every finding of the function is suppressed.
+const ignoreDirective = "//cfg:ignore"
+
+// Rules describes every rule that may produce a finding, keyed by rule ID.
+var Rules = map[string]string{
+	"cyclomatic-complexity": "Function cyclomatic complexity exceeds the configured threshold",
+	"infinite-loop":         "Loop has no reachable exit",
+	"duplicate-condition":   "Condition repeats an earlier condition of the same if-else chain",
+	"unused-shadowed":       "Variable is shadowed by an inner declaration before it is used",
+	"empty-branch":          "Branch of an if statement has an empty body",
+	"simplifiable-bool":     "Boolean expression has a simpler equivalent",
+	"too-many-statements":   "Function has more statements than the configured threshold",
+	"condition-call":        "Condition calls a function that may have side effects",
+	"dead-store":            "Value assigned to a variable is never used",
+	"use-before-def":        "Variable may be used before it is defined",
+}
+
+// checkFunc runs the rule checks on fn, declared in file, and its CFG.
+// Findings matching an ignore directive of fn are marked as suppressed.
+func (a *analyzer) checkFunc(fset *token.FileSet, file *ast.File, fn *ast.FuncDecl, cg *cfg.CFG) []Finding {
+	var findings []Finding
+	if complexity, _, _ := cyclomatic(cg); a.opts.MaxComplexity > 0 && complexity > a.opts.MaxComplexity {
+		findings = append(findings, newFinding(fset, fn.Name, "cyclomatic-complexity", "warning",
+			fmt.Sprintf("function %s has cyclomatic complexity %d (threshold %d)", funcName(fn), complexity, a.opts.MaxComplexity)))
+	}
+	if statements, _ := statementCount(fn); a.opts.MaxStatements > 0 && statements > a.opts.MaxStatements {
+		findings = append(findings, newFinding(fset, fn.Name, "too-many-statements", "warning",
+			fmt.Sprintf("function %s has %d statements (threshold %d)", funcName(fn), statements, a.opts.MaxStatements)))
+	}
+	for _, loop := range infiniteLoops(cg) {
+		findings = append(findings, newFinding(fset, loop, "infinite-loop", "warning",
+			"loop never exits: no break, return or loop condition leads out of it"))
+	}
+	for _, body := range emptyBranches(cg) {
+		findings = append(findings, newFinding(fset, body, "empty-branch", "warning",
+			"empty branch: the block does nothing; remove it or invert the condition"))
+	}
+
+	findings = append(findings, duplicateConditions(fset, fn)...)
+	findings = append(findings, unusedShadows(fset, fn)...)
+	findings = append(findings, simplifiableBools(fset, fn)...)
+	findings = append(findings, conditionCalls(fset, file, fn)...)
+	findings = append(findings, dataFlowFindings(fset, fn, cg)...)
+
+	ignored := ignoredRules(fset, file, fn)
+	for i := range findings {
+		findings[i].Suppressed = isIgnored(ignored, findings[i].RuleID)
+	}
+	return findings
+}
+
+// ignoredRules collects the rule names listed by the ignore directives of fn:
+// those in its doc comment and those on the line of the func keyword. A nil
+// entry in the result means all rules are ignored.
+func ignoredRules(fset *token.FileSet, file *ast.File, fn *ast.FuncDecl) [][]string {
+	var groups []*ast.CommentGroup
+	if fn.Doc != nil {
+		groups = append(groups, fn.Doc)
+	}
+	if file != nil {
+		line := fset.Position(fn.Pos()).Line
+		for _, group := range file.Comments {
+			if group != fn.Doc && fset.Position(group.Pos()).Line == line {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	var ignored [][]string
+	for _, group := range groups {
+		for _, comment := range group.List {
+			if args, ok := strings.CutPrefix(comment.Text, ignoreDirective); ok && (args == "" || args[0] == ' ') {
+				ignored = append(ignored, strings.Fields(args))
+			}
+		}
+	}
+	return ignored
+}
+
+// isIgnored reports whether ruleID is covered by the ignored rule lists. A
+// rule name matches the full rule ID or its last dash-separated component, so
+// "complexity" suppresses "cyclomatic-complexity".
+func isIgnored(ignored [][]string, ruleID string) bool {
+	for _, names := range ignored {
+		if len(names) == 0 {
+			return true
+		}
+		for _, name := range names {
+			if name == ruleID || strings.HasSuffix(ruleID, "-"+name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findingJSON is the JSON form of a Finding, as written by -format
+// findings-json and in -stdin-batch reports.
+type findingJSON struct {
+	RuleID     string       `json:"rule_id"`
+	Message    string       `json:"message"`
+	Severity   string       `json:"severity"`
+	Pos        positionJSON `json:"pos"`
+	End        positionJSON `json:"end"`
+	Suppressed bool         `json:"suppressed,omitempty"`
+}
+
+type positionJSON struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// MarshalJSON encodes f in its findingJSON form.
+func (f Finding) MarshalJSON() ([]byte, error) {
+	return json.Marshal(findingJSON{
+		RuleID:     f.RuleID,
+		Message:    f.Message,
+		Severity:   f.Severity,
+		Pos:        positionJSON{f.Pos.Filename, f.Pos.Line, f.Pos.Column},
+		End:        positionJSON{f.End.Filename, f.End.Line, f.End.Column},
+		Suppressed: f.Suppressed,
+	})
+}
+
+func newFinding(fset *token.FileSet, node ast.Node, ruleID, severity, message string) Finding {
+	return Finding{
+		RuleID:   ruleID,
+		Message:  message,
+		Severity: severity,
+		Pos:      fset.Position(node.Pos()),
+		End:      fset.Position(node.End()),
+	}
+}
+
+// printFindings writes the unsuppressed findings of a function to the text
+// report w.
+func printFindings(w io.Writer, findings []Finding) {
+	if len(findings) == SuppressedCount(findings) {
+		return
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintln(w, "Findings:")
+	for _, f := range findings {
+		if f.Suppressed {
+			continue
+		}
+		fmt.Fprintf(w, "  %s: %s: %s [%s]\n", f.Pos, f.Severity, f.Message, f.RuleID)
+	}
+}
+
+// SuppressedCount returns the number of suppressed findings.
+func SuppressedCount(findings []Finding) int {
+	count := 0
+	for _, f := range findings {
+		if f.Suppressed {
+			count++
+		}
+	}
+	return count
+}
+
+// infiniteLoops returns the for loops of cg from whose body neither the block
+// after the loop nor any exit of the function (a block without successors,
+// such as a return) can be reached.
+func infiniteLoops(cg *cfg.CFG) []*ast.ForStmt {
+	var loops []*ast.ForStmt
+	for _, block := range cg.Blocks {
+		forStmt, ok := block.Stmt.(*ast.ForStmt)
+		if !ok || block.Kind != cfg.KindForBody || !block.Live {
+			continue
+		}
+		escapes := false
+		visited := map[int32]bool{block.Index: true}
+		queue := []*cfg.Block{block}
+		for len(queue) > 0 && !escapes {
+			current := queue[0]
+			queue = queue[1:]
+			if len(current.Succs) == 0 || (current.Kind == cfg.KindForDone && current.Stmt == forStmt) {
+				escapes = true
+			}
+			for _, succ := range current.Succs {
+				if !visited[succ.Index] {
+					visited[succ.Index] = true
+					queue = append(queue, succ)
+				}
+			}
+		}
+		if !escapes {
+			loops = append(loops, forStmt)
+		}
+	}
+	return loops
+}
+
+// duplicateConditions reports conditions of if-else chains that render the
+// same as an earlier condition of the chain; the later branch can never be
+// taken. An else-if with an init statement starts the comparison afresh, as
+// the init may redeclare or change what the earlier conditions tested.
+func duplicateConditions(fset *token.FileSet, fn *ast.FuncDecl) []Finding {
+	var findings []Finding
+	elseIfs := make(map[*ast.IfStmt]bool)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		ifStmt, ok := n.(*ast.IfStmt)
+		if !ok || elseIfs[ifStmt] {
+			return true
+		}
+		seen := make(map[string]token.Position)
+		for current := ifStmt; current != nil; {
+			if current.Init != nil {
+				seen = make(map[string]token.Position)
+			}
+			// Compare the full text: labels may abbreviate selectors.
+			cond, key := getValue(current.Cond), types.ExprString(current.Cond)
+			if first, ok := seen[key]; ok {
+				findings = append(findings, newFinding(fset, current.Cond, "duplicate-condition", "warning",
+					fmt.Sprintf("condition %q already tested at line %d; this branch is unreachable", cond, first.Line)))
+			} else {
+				seen[key] = fset.Position(current.Cond.Pos())
+			}
+			next, _ := current.Else.(*ast.IfStmt)
+			if next != nil {
+				elseIfs[next] = true
+			}
+			current = next
+		}
+		return true
+	})
+	return findings
+}
+
+// emptyBranches returns the then and else bodies of the if statements of cg
+// whose blocks have no nodes and that contain no statements at all; the
+// second check keeps branches holding only a break, continue or goto, which
+// go/cfg turns into edges rather than nodes.
+func emptyBranches(cg *cfg.CFG) []*ast.BlockStmt {
+	var bodies []*ast.BlockStmt
+	for _, block := range cg.Blocks {
+		ifStmt, ok := block.Stmt.(*ast.IfStmt)
+		if !ok || !block.Live || len(block.Nodes) > 0 {
+			continue
+		}
+		var body *ast.BlockStmt
+		switch block.Kind {
+		case cfg.KindIfThen:
+			body = ifStmt.Body
+		case cfg.KindIfElse:
+			body, _ = ifStmt.Else.(*ast.BlockStmt)
+		}
+		if body != nil && len(body.List) == 0 {
+			bodies = append(bodies, body)
+		}
+	}
+	return bodies
+}
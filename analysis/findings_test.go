@@ -0,0 +1,141 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDuplicateConditions(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []int // lines of the duplicates
+	}{
+		{"repeated condition", "if x > 0 {\n} else if y > 0 {\n} else if x > 0 {\n}", []int{3}},
+		{"distinct conditions", "if x > 0 {\n} else if x < 0 {\n}", nil},
+		{"init redeclares", "if x := f(); x > 0 {\n} else if x := g(); x > 0 {\n}", nil},
+		{"init on first only", "if x := f(); x > 0 {\n} else if x > 0 {\n}", []int{2}},
+		{"separate statements", "if x > 0 {\n}\nif x > 0 {\n}", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			findings := duplicateConditions(fset, fn)
+			if len(findings) != len(test.want) {
+				t.Fatalf("got %d findings %v, want lines %v", len(findings), findings, test.want)
+			}
+			for i, finding := range findings {
+				if finding.Pos.Line != test.want[i] {
+					t.Errorf("finding %d at line %d, want %d", i, finding.Pos.Line, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInfiniteLoops(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"no exit", "for {\n\tx()\n}", 1},
+		{"break", "for {\n\tif a > b {\n\t\tbreak\n\t}\n}", 0},
+		{"return", "for {\n\tif a > b {\n\t\treturn\n\t}\n}", 0},
+		{"condition", "for i < 3 {\n\tx()\n}", 0},
+		{"inner loop exits", "for {\n\tfor i < 3 {\n\t\tx()\n\t}\n}", 1},
+		{"break outer", "Outer:\n\tfor {\n\t\tfor {\n\t\t\tbreak Outer\n\t\t}\n\t}", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, fn := parseSnippet(t, test.body)
+			if got := len(infiniteLoops(newCFG(fn))); got != test.want {
+				t.Errorf("%d infinite loops, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUnusedShadows(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []int // lines of the shadowing declarations
+	}{
+		{"shadowed before use", "err := f()\nif ok {\n\terr := g()\n\t_ = err\n}", []int{3}},
+		{"used before shadowing", "err := f()\nprintln(err)\nif ok {\n\terr := g()\n\t_ = err\n}", nil},
+		{"assignment is no use", "x := 1\nx = 2\nfor range s {\n\tvar x int\n\t_ = x\n}", []int{4}},
+		{"if header", "v := f()\nif v := g(); v > 0 {\n}", []int{2}},
+		{"function literal", "n := 0\nfunc() {\n\tn := 1\n\t_ = n\n}()", []int{3}},
+		{"same scope", "a := 1\na, b := 2, 3\n_, _ = a, b", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			var lines []int
+			for _, finding := range unusedShadows(fset, fn) {
+				lines = append(lines, finding.Pos.Line)
+			}
+			if !slices.Equal(lines, test.want) {
+				t.Errorf("findings at lines %v, want %v", lines, test.want)
+			}
+		})
+	}
+}
+
+func TestEmptyBranches(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []int // lines of the empty bodies
+	}{
+		{"empty then", "if x > 0 {\n}\ny()", []int{1}},
+		{"empty else", "if x > 0 {\n\ty()\n} else {\n}", []int{3}},
+		{"both empty", "if x > 0 {\n} else {\n}", []int{1, 2}},
+		{"non-empty", "if x > 0 {\n\ty()\n} else {\n\tz()\n}", nil},
+		{"only break", "for {\n\tif x > 0 {\n\t\tbreak\n\t}\n}", nil},
+		{"else if", "if x > 0 {\n\ty()\n} else if x < 0 {\n}", []int{3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			var got []int
+			for _, body := range emptyBranches(newCFG(fn)) {
+				got = append(got, fset.Position(body.Pos()).Line)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("empty branches at lines %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestConditionCalls(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []string // messages
+	}{
+		{"if call", "if ok() {\n}", []string{"if condition calls ok, which may have side effects"}},
+		{"method call", "if r.Next() {\n}", []string{"if condition calls r.Next, which may have side effects"}},
+		{"for call", "for scan() {\n}", []string{"for condition calls scan, which may have side effects"}},
+		{"switch tag", "switch read() {\ncase 1:\n}", []string{"switch condition calls read, which may have side effects"}},
+		{"nested", "if f(g(x)) {\n}", []string{
+			"if condition calls f, which may have side effects",
+			"if condition calls g, which may have side effects",
+		}},
+		{"pure builtins", "if len(s) > cap(t) && min(a, b) > 0 {\n}", nil},
+		{"conversion", "if int64(x) > 0 && []byte(s)[0] == 'a' {\n}", nil},
+		{"function literal", "if func() bool { return f() }() {\n}", []string{"if condition calls func() bool {…}, which may have side effects"}},
+		{"init statement", "if v := f(); v > 0 {\n}", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, file, fn := parseSnippet(t, test.body)
+			findings := conditionCalls(fset, file, fn)
+			var got []string
+			for _, finding := range findings {
+				got = append(got, finding.Message)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("findings %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package analysis
+
+import (
+	"go/ast"
+	"slices"
+	"testing"
+)
+
+func TestGoStmt(t *testing.T) {
+	fset, _, fn := parseSnippet(t, "x := 0\ngo func() {\n\tx = 1\n}()\ngo work(x)")
+	cg := newCFG(fn)
+	dot, sets := defaultAnalyzer().genDot(fset, fn, cg, nil)
+	labels := dotLabels(dot)
+	for _, want := range []string{"go func() {…}()", "go work(x)"} {
+		if !slices.Contains(mapValues(labels), want) {
+			t.Errorf("no node labeled %q in\n%s", want, dot)
+		}
+	}
+	if !sets.modified["x"] {
+		t.Errorf("x written by the goroutine is not modified: %v", sets.modified)
+	}
+
+	var stmts []*ast.GoStmt
+	for _, block := range cg.Blocks {
+		for _, node := range block.Nodes {
+			if stmt, ok := node.(*ast.GoStmt); ok {
+				stmts = append(stmts, stmt)
+			}
+		}
+	}
+	if len(stmts) != 2 {
+		t.Fatalf("got %d go statements in the CFG, want 2", len(stmts))
+	}
+	tests := []struct {
+		stmt       *ast.GoStmt
+		defs, uses []string
+	}{
+		{stmts[0], []string{"x"}, nil},
+		{stmts[1], nil, []string{"x"}},
+	}
+	for _, test := range tests {
+		defs, uses := defsUses(test.stmt, newVarNamer(nil), nil)
+		if !slices.Equal(defs, test.defs) || !slices.Equal(uses, test.uses) {
+			t.Errorf("defsUses(%s) = %v, %v; want %v, %v", getValue(test.stmt.Call), defs, uses, test.defs, test.uses)
+		}
+	}
+}
+
+// mapValues returns the values of m in no particular order.
+func mapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, value := range m {
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// unstructuredLoops classifies the loops of cg that are not for or range
+// statements. go/cfg draws goto statements as ordinary edges, so their back
+// edges already count towards the cyclomatic complexity; here they are told
+// apart from structured loops:
+//
+//   - a goto loop is closed by a back edge (one whose target dominates its
+//     source) to a block that is not part of a for or range statement, as
+//     in "L: ...; if c { goto L }";
+//   - an irreducible loop is entered by a retreating edge whose target does
+//     not dominate its source, i.e. a cycle with more than one entry, which
+//     only a goto into it can produce.
+//
+// The counts are of loop heads: several back edges to the same head make a
+// single loop.
+func unstructuredLoops(cg *cfg.CFG) (gotoLoops, irreducible int) {
+	order := executionOrder(cg)
+	rpo := make(map[int32]int)
+	for i, block := range order {
+		rpo[block.Index] = i
+	}
+	idom := dominators(cg)
+	dominates := func(a, b int32) bool {
+		for {
+			if a == b {
+				return true
+			}
+			parent, ok := idom[b]
+			if !ok {
+				return false
+			}
+			b = parent
+		}
+	}
+
+	gotoHeads := make(map[int32]bool)
+	irreducibleHeads := make(map[int32]bool)
+	for _, block := range order {
+		for _, succ := range block.Succs {
+			if rpo[succ.Index] > rpo[block.Index] {
+				continue
+			}
+			switch {
+			case !dominates(succ.Index, block.Index):
+				irreducibleHeads[succ.Index] = true
+			case !isLoopStmt(succ.Stmt):
+				gotoHeads[succ.Index] = true
+			}
+		}
+	}
+	return len(gotoHeads), len(irreducibleHeads)
+}
+
+// isLoopStmt reports whether stmt, the statement of a block, is a for or
+// range statement. go/cfg closes a loop at its condition, post statement or,
+// for a for without condition, its body.
+func isLoopStmt(stmt ast.Stmt) bool {
+	switch stmt.(type) {
+	case *ast.ForStmt, *ast.RangeStmt:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package analysis
+
+import "testing"
+
+func TestUnstructuredLoops(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		gotos, irreducible int
+	}{
+		{"goto loop", "i := 0\nL:\n\ti++\n\tif i < 3 {\n\t\tgoto L\n\t}", 1, 0},
+		{"for without condition", "for {\n\tx()\n}", 0, 0},
+		{"for with condition", "for i := 0; i < 3; i++ {\n\tx()\n}", 0, 0},
+		{"range with continue", "for i := range 3 {\n\tif i == 1 {\n\t\tcontinue\n\t}\n}", 0, 0},
+		{"jump into loop", "i := 0\nif i > 3 {\n\tgoto B\n}\nA:\n\ti++\nB:\n\ti += 2\n\tif i < 10 {\n\t\tgoto A\n\t}", 0, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, fn := parseSnippet(t, test.body)
+			cg := newCFG(fn)
+			gotos, irreducible := unstructuredLoops(cg)
+			if gotos != test.gotos || irreducible != test.irreducible {
+				t.Errorf("unstructuredLoops = %d goto, %d irreducible; want %d, %d", gotos, irreducible, test.gotos, test.irreducible)
+			}
+			// go/cfg draws goto as edges, so the back edge adds to the
+			// cyclomatic complexity like a loop does.
+			if complexity, _, _ := cyclomatic(cg); test.gotos > 0 && complexity != 2 {
+				t.Errorf("cyclomatic complexity = %d, want 2", complexity)
+			}
+		})
+	}
+}
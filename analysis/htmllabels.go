@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"html"
+	"strings"
+)
+
+// htmlLabelDot rewrites the node labels of the DOT output of genDot as
+// Graphviz HTML-like labels, coloring keywords, identifiers and literals
+// with the colors of the selected scheme.
+func (a *analyzer) htmlLabelDot(dot string) string {
+	unescape := strings.NewReplacer(`\"`, `"`, `\\`, `\`)
+	return dotNodeLabel.ReplaceAllStringFunc(dot, func(stmt string) string {
+		match := dotNodeLabel.FindStringSubmatch(stmt)
+		return fmt.Sprintf("  %s [label=<%s>];", match[1], a.highlight(unescape.Replace(match[2])))
+	})
+}
+
+// highlight tokenizes label as Go source and returns it as HTML-like label
+// text with a font span around every keyword, identifier and literal. Text
+// the scanner does not recognize, such as an ellipsis, is kept as it is.
+func (a *analyzer) highlight(label string) string {
+	c := a.colors()
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(label))
+	var s scanner.Scanner
+	s.Init(file, []byte(label), func(token.Position, string) {}, 0)
+
+	var sb strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			// An automatically inserted semicolon, not part of the label.
+			continue
+		}
+		start := file.Offset(pos)
+		end := start + len(tok.String())
+		if lit != "" {
+			end = start + len(lit)
+		}
+		if start < last || end > len(label) {
+			continue
+		}
+		sb.WriteString(html.EscapeString(label[last:start]))
+		text := html.EscapeString(label[start:end])
+		var color string
+		switch {
+		case tok.IsKeyword(), start == 0 && lit == "Return":
+			// genDot labels return statements "Return: ...".
+			color = c.keyword
+		case tok == token.IDENT:
+			color = c.identifier
+		case tok.IsLiteral():
+			color = c.literal
+		}
+		if color != "" {
+			fmt.Fprintf(&sb, `<font color="%s">%s</font>`, color, text)
+		} else {
+			sb.WriteString(text)
+		}
+		last = end
+	}
+	sb.WriteString(html.EscapeString(label[last:]))
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		label, want string
+	}{
+		{"x = 1", `<font color="darkgreen">x</font> = <font color="darkorange">1</font>`},
+		{"for i < n", `<font color="blue">for</font> <font color="darkgreen">i</font> &lt; <font color="darkgreen">n</font>`},
+		{"Return: err", `<font color="blue">Return</font>: <font color="darkgreen">err</font>`},
+		{`s = "a&b"`, `<font color="darkgreen">s</font> = <font color="darkorange">&#34;a&amp;b&#34;</font>`},
+		{"f(xs...)", `<font color="darkgreen">f</font>(<font color="darkgreen">xs</font>...)`},
+	}
+	for _, test := range tests {
+		t.Run(test.label, func(t *testing.T) {
+			if got := defaultAnalyzer().highlight(test.label); got != test.want {
+				t.Errorf("highlight(%q) = %s, want %s", test.label, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHTMLLabelDot(t *testing.T) {
+	dot := defaultAnalyzer().htmlLabelDot(snippetDot(t, DefaultOptions(), `s = "q"`))
+	if !strings.Contains(dot, `[label=<<font color="darkgreen">s</font> = <font color="darkorange">&#34;q&#34;</font>>];`) {
+		t.Errorf("label not rewritten as HTML-like label with the quotes unescaped:\n%s", dot)
+	}
+}
@@ -0,0 +1,130 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// IfRenderings lists the values of Options.IfEdges: "cfg" draws the
+// branches of an if along the block successors, "ast" descends into
+// IfStmt.Body and IfStmt.Else to find where each branch starts.
+var IfRenderings = []string{"cfg", "ast"}
+
+// ifStmtOf returns the if statement whose condition is the last node of
+// block, or nil if block does not end in an if condition.
+func ifStmtOf(block *cfg.Block) *ast.IfStmt {
+	if len(block.Nodes) == 0 {
+		return nil
+	}
+	last := block.Nodes[len(block.Nodes)-1]
+	for _, succ := range block.Succs {
+		if succ.Kind != cfg.KindIfThen && succ.Kind != cfg.KindIfElse {
+			continue
+		}
+		if ifStmt, ok := succ.Stmt.(*ast.IfStmt); ok && ast.Node(ifStmt.Cond) == last {
+			return ifStmt
+		}
+	}
+	return nil
+}
+
+// firstNode returns the CFG node that is evaluated first when stmt runs, or
+// nil if stmt adds no node of its own (an empty block, a branch statement,
+// a switch without tag, ...).
+func firstNode(stmt ast.Stmt) ast.Node {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt, *ast.ExprStmt, *ast.IncDecStmt, *ast.ReturnStmt,
+		*ast.SendStmt, *ast.GoStmt, *ast.DeferStmt, *ast.EmptyStmt:
+		return s
+	case *ast.DeclStmt:
+		if decl := s.Decl.(*ast.GenDecl); len(decl.Specs) > 0 {
+			return decl.Specs[0]
+		}
+	case *ast.BlockStmt:
+		if len(s.List) > 0 {
+			return firstNode(s.List[0])
+		}
+	case *ast.LabeledStmt:
+		return firstNode(s.Stmt)
+	case *ast.IfStmt:
+		if s.Init != nil {
+			return firstNode(s.Init)
+		}
+		return s.Cond
+	case *ast.ForStmt:
+		if s.Init != nil {
+			return firstNode(s.Init)
+		}
+		if s.Cond != nil {
+			return s.Cond
+		}
+	case *ast.RangeStmt:
+		return s.X
+	case *ast.SwitchStmt:
+		if s.Init != nil {
+			return firstNode(s.Init)
+		}
+		if s.Tag != nil {
+			return s.Tag
+		}
+	case *ast.TypeSwitchStmt:
+		if s.Init != nil {
+			return firstNode(s.Init)
+		}
+		return s.Assign
+	}
+	return nil
+}
+
+// astIfEdges draws the then and else edges leaving the condition node condID
+// of ifStmt, whose condition ends block. Each edge goes to the first node of
+// the corresponding branch in the syntax tree; a branch without nodes of its
+// own, such as an empty body or a missing else, falls back to the CFG
+// successor of the matching kind.
+func (a *analyzer) astIfEdges(cg *cfg.CFG, block *cfg.Block, ifStmt *ast.IfStmt, condID string, nodeIDs map[ast.Node]string) string {
+	dot := ""
+	branch := func(stmt ast.Stmt, kind cfg.BlockKind, color, label string) {
+		var target, weight string
+		if succ := succOfKind(block, kind); succ != nil {
+			weight = a.probabilityAttrs(cg, block, succ)
+		}
+		if node := firstNode(stmt); node != nil {
+			target = nodeIDs[node]
+		}
+		if target == "" {
+			if succ := succOfKind(block, kind); succ != nil {
+				target = entryNodeID(cg, int(succ.Index))
+			}
+		}
+		if target != "" {
+			dot += fmt.Sprintf("  %s -> %s [label=\"%s\" color=\"%s\"%s];\n", condID, target, label, color, weight)
+		}
+	}
+	then, otherwise := "then", "else"
+	if a.opts.BranchLabels {
+		then, otherwise = "true", "false"
+	}
+	branch(ifStmt.Body, cfg.KindIfThen, a.colors().then, then)
+	if ifStmt.Else != nil {
+		branch(ifStmt.Else, cfg.KindIfElse, a.colors().otherwise, otherwise)
+	} else {
+		branch(nil, cfg.KindIfDone, a.colors().otherwise, otherwise)
+	}
+	return dot
+}
+
+// cfgNodeIDs maps every node of the live blocks of cg to its DOT node ID.
+func cfgNodeIDs(cg *cfg.CFG) map[ast.Node]string {
+	ids := make(map[ast.Node]string)
+	for _, block := range cg.Blocks {
+		if !block.Live {
+			continue
+		}
+		for i, node := range block.Nodes {
+			ids[node] = fmt.Sprintf("block_%d_node_%d", block.Index, i)
+		}
+	}
+	return ids
+}
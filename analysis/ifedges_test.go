@@ -0,0 +1,40 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestASTIfEdges(t *testing.T) {
+	opts := DefaultOptions()
+	opts.IfEdges = "ast"
+	tests := []struct {
+		name, body, cond string
+		want             []dotEdge
+	}{
+		{"if else", "if a > b {\n\tx()\n} else {\n\ty()\n}", "a > b", []dotEdge{
+			{"a > b", "x()", `label="then" color="yellow"`},
+			{"a > b", "y()", `label="else" color="red"`},
+		}},
+		{"else if with init", "if a > b {\n\tx()\n} else if v := g(); v > 0 {\n\ty()\n}", "a > b", []dotEdge{
+			{"a > b", "x()", `label="then" color="yellow"`},
+			{"a > b", "v = g()", `label="else" color="red"`},
+		}},
+		{"empty body", "if a > b {\n}\nz()", "a > b", []dotEdge{
+			{"a > b", "z()", `label="then" color="yellow"`},
+			{"a > b", "z()", `label="else" color="red"`},
+		}},
+		{"nested statement", "if a > b {\n\tfor i < 3 {\n\t\tx()\n\t}\n}\nz()", "a > b", []dotEdge{
+			{"a > b", "for i < 3", `label="then" color="yellow"`},
+			{"a > b", "z()", `label="else" color="red"`},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dot := snippetDot(t, opts, test.body)
+			if got := edgesFrom(dot, test.cond); !slices.Equal(got, test.want) {
+				t.Errorf("edges %v, want %v\n%s", got, test.want, dot)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// initializerFuncs returns synthetic functions for the package-level var
+// initializers of file that contain logic, i.e. calls or function literals,
+// so that -initializers can analyze them like functions. Each such
+// declaration yields a function "var x" whose body assigns the initial
+// values, plus one function "var x.funcN" per function literal in the values,
+// whose body is the literal's body.
+func initializerFuncs(file *ast.File) []*ast.FuncDecl {
+	var funcs []*ast.FuncDecl
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			if len(valueSpec.Values) == 0 || !hasLogic(valueSpec.Values) {
+				continue
+			}
+			names := []string{}
+			lhs := []ast.Expr{}
+			for _, name := range valueSpec.Names {
+				names = append(names, name.Name)
+				lhs = append(lhs, name)
+			}
+			name := "var " + strings.Join(names, ", ")
+			funcs = append(funcs, &ast.FuncDecl{
+				Name: &ast.Ident{NamePos: valueSpec.Pos(), Name: name},
+				Type: &ast.FuncType{Func: valueSpec.Pos(), Params: &ast.FieldList{}},
+				Body: &ast.BlockStmt{
+					Lbrace: valueSpec.Pos(),
+					List:   []ast.Stmt{&ast.AssignStmt{Lhs: lhs, TokPos: valueSpec.Pos(), Tok: token.ASSIGN, Rhs: valueSpec.Values}},
+					Rbrace: valueSpec.End() - 1,
+				},
+			})
+			literals := 0
+			for _, value := range valueSpec.Values {
+				ast.Inspect(value, func(n ast.Node) bool {
+					lit, ok := n.(*ast.FuncLit)
+					if !ok {
+						return true
+					}
+					literals++
+					funcs = append(funcs, &ast.FuncDecl{
+						Name: &ast.Ident{NamePos: lit.Pos(), Name: fmt.Sprintf("%s.func%d", name, literals)},
+						Type: lit.Type,
+						Body: lit.Body,
+					})
+					return false
+				})
+			}
+		}
+	}
+	return funcs
+}
+
+// hasLogic reports whether any of values contains a call or a function
+// literal.
+func hasLogic(values []ast.Expr) bool {
+	found := false
+	for _, value := range values {
+		ast.Inspect(value, func(n ast.Node) bool {
+			switch n.(type) {
+			case *ast.CallExpr, *ast.FuncLit:
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInitializerFuncs(t *testing.T) {
+	_, file, _ := parseSource(t, `package p
+
+var a = 1
+
+var b, c = f(), 2
+
+var (
+	h = func(x int) int {
+		if x < 0 {
+			x = -x
+		}
+		return x
+	}
+	s []string
+)
+
+const k = len("k")
+
+func init() {
+	a = 2
+}
+`)
+	var names []string
+	for _, fn := range initializerFuncs(file) {
+		names = append(names, funcName(fn))
+		if fn.Body == nil || len(fn.Body.List) == 0 {
+			t.Errorf("%s has no body", funcName(fn))
+			continue
+		}
+		if complexity, _, _ := cyclomatic(newCFG(fn)); funcName(fn) == "var h.func1" && complexity != 2 {
+			t.Errorf("%s has cyclomatic complexity %d, want 2", funcName(fn), complexity)
+		}
+	}
+	want := []string{"var b, c", "var h", "var h.func1"}
+	if !slices.Equal(names, want) {
+		t.Errorf("initializer functions %v, want %v", names, want)
+	}
+}
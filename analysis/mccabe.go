@@ -0,0 +1,119 @@
+package analysis
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// decisionKinds lists the kinds of decision points counted by astDecisions,
+// in reporting order.
+var decisionKinds = []string{"if", "for", "range", "case", "comm", "&&", "||"}
+
+// astDecisions counts the decision points of fn by kind: if, for and range
+// statements, non-default switch and select cases, and the && and ||
+// operators. Function literals are included.
+func astDecisions(fn *ast.FuncDecl) map[string]int {
+	counts := make(map[string]int)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			counts["if"]++
+		case *ast.ForStmt:
+			counts["for"]++
+		case *ast.RangeStmt:
+			counts["range"]++
+		case *ast.CaseClause:
+			if n.List != nil {
+				counts["case"]++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				counts["comm"]++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				counts[n.Op.String()]++
+			}
+		}
+		return true
+	})
+	return counts
+}
+
+// astCyclomatic returns McCabe's complexity counted on the syntax tree: one
+// plus the number of decision points.
+func astCyclomatic(fn *ast.FuncDecl) int {
+	complexity := 1
+	for _, count := range astDecisions(fn) {
+		complexity += count
+	}
+	return complexity
+}
+
+// unbranchedLogicalOps returns the && and || operators of fn in source
+// order. go/cfg does not split conditions into their operands, so every one
+// of them adds to the AST count but not to the CFG one.
+func unbranchedLogicalOps(fn *ast.FuncDecl) []*ast.BinaryExpr {
+	var ops []*ast.BinaryExpr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if n, ok := n.(*ast.BinaryExpr); ok && (n.Op == token.LAND || n.Op == token.LOR) {
+			ops = append(ops, n)
+		}
+		return true
+	})
+	slices.SortFunc(ops, func(a, b *ast.BinaryExpr) int { return cmp.Compare(a.OpPos, b.OpPos) })
+	return ops
+}
+
+// cyclomaticDifferences returns the known causes of a difference between
+// the AST-based and the CFG-based complexity of fn, one line each.
+func cyclomaticDifferences(fset *token.FileSet, fn *ast.FuncDecl, cg *cfg.CFG) []string {
+	var causes []string
+	for _, op := range unbranchedLogicalOps(fn) {
+		causes = append(causes, fmt.Sprintf("%s at %s does not branch in the CFG", op.Op, fset.Position(op.OpPos)))
+	}
+	if dead := deadBlocks(cg); dead > 0 {
+		causes = append(causes, fmt.Sprintf("%d unreachable blocks are not counted in the CFG", dead))
+	}
+	return causes
+}
+
+// printCyclomaticComparison prints the AST-based complexity of fn next to the
+// CFG-based one and, when they differ, the known causes of a difference.
+func printCyclomaticComparison(w io.Writer, fset *token.FileSet, fn *ast.FuncDecl, cg *cfg.CFG) {
+	decisions := astDecisions(fn)
+	parts := []string{}
+	for _, kind := range decisionKinds {
+		if decisions[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", kind, decisions[kind]))
+		}
+	}
+	astComplexity := astCyclomatic(fn)
+	fmt.Fprintf(w, "AST Cyclomatic Complexity: %d (1 + decisions: %s)\n", astComplexity, strings.Join(parts, ", "))
+	complexity, _, _ := cyclomatic(cg)
+	if complexity == astComplexity {
+		return
+	}
+	fmt.Fprintf(w, "  differs from the CFG count %d by %+d:\n", complexity, astComplexity-complexity)
+	for _, cause := range cyclomaticDifferences(fset, fn, cg) {
+		fmt.Fprintf(w, "  - %s\n", cause)
+	}
+}
+
+// deadBlocks counts the blocks of cg that cannot be reached.
+func deadBlocks(cg *cfg.CFG) int {
+	dead := 0
+	for _, block := range cg.Blocks {
+		if !block.Live {
+			dead++
+		}
+	}
+	return dead
+}
@@ -0,0 +1,23 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCyclomaticDifferencesListLogicalOps(t *testing.T) {
+	fset, _, fn := parseSnippet(t, "if a > 0 && b > 0 || c {\n}\nx := d && e")
+	cg := newCFG(fn)
+	want := []string{
+		"&& at snippet.go:1:10 does not branch in the CFG",
+		"|| at snippet.go:1:19 does not branch in the CFG",
+		"&& at snippet.go:3:8 does not branch in the CFG",
+	}
+	if got := cyclomaticDifferences(fset, fn, cg); !slices.Equal(got, want) {
+		t.Errorf("cyclomaticDifferences = %q, want %q", got, want)
+	}
+	complexity, _, _ := cyclomatic(cg)
+	if got := astCyclomatic(fn) - complexity; got != len(want) {
+		t.Errorf("AST and CFG counts differ by %d, want %d", got, len(want))
+	}
+}
@@ -0,0 +1,215 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// blockSizeCounter accumulates basic-block statistics as a NodeVisitor.
+type blockSizeCounter struct {
+	sizes map[int32]int
+}
+
+func newBlockSizeCounter() *blockSizeCounter {
+	return &blockSizeCounter{sizes: make(map[int32]int)}
+}
+
+func (c *blockSizeCounter) visit(node ast.Node, block *cfg.Block) {
+	if node == nil {
+		c.sizes[block.Index] = 0
+		return
+	}
+	c.sizes[block.Index]++
+}
+
+// blockSizes returns the number of nodes in the largest live block of cg, the
+// average number of nodes per live block and the number of live blocks
+// without nodes.
+func blockSizes(cg *cfg.CFG) (largest int, average float64, empty int) {
+	counter := newBlockSizeCounter()
+	walkCFG(cg, counter.visit)
+	return counter.stats()
+}
+
+// stats returns the statistics of the blocks visited so far, as blockSizes.
+func (c *blockSizeCounter) stats() (largest int, average float64, empty int) {
+	total := 0
+	for _, size := range c.sizes {
+		total += size
+		largest = max(largest, size)
+		if size == 0 {
+			empty++
+		}
+	}
+	if len(c.sizes) > 0 {
+		average = float64(total) / float64(len(c.sizes))
+	}
+	return largest, average, empty
+}
+
+func printBlockSizes(w io.Writer, cg *cfg.CFG) {
+	largest, average, empty := blockSizes(cg)
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintf(w, "Largest Block: %d nodes.\n", largest)
+	fmt.Fprintf(w, "Average Block Size: %.2f nodes.\n", average)
+	fmt.Fprintf(w, "Empty Blocks: %d.\n", empty)
+}
+
+// printControlVars prints the control variables ranked by the number of
+// decision points referencing them, with the positions of those points.
+func printControlVars(w io.Writer, controlVars map[string]int, sites map[string][]token.Position) {
+	names := make([]string, 0, len(controlVars))
+	for name := range controlVars {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if controlVars[names[i]] != controlVars[names[j]] {
+			return controlVars[names[i]] > controlVars[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	fmt.Fprintln(w, "Control variables by decision points:")
+	for _, name := range names {
+		positions := sites[name]
+		sort.Slice(positions, func(i, j int) bool { return positions[i].Line < positions[j].Line })
+		lines := []string{}
+		for _, pos := range positions {
+			lines = append(lines, strconv.Itoa(pos.Line))
+		}
+		fmt.Fprintf(w, "  %s: %d (lines %s)\n", name, controlVars[name], strings.Join(lines, ", "))
+	}
+}
+
+// maxExpressionDepth returns the deepest nesting of binary, unary, call and
+// parenthesized expressions within fn and the position of the expression
+// where it occurs. Function literals start a new expression context.
+func maxExpressionDepth(fn *ast.FuncDecl) (depth int, pos token.Pos) {
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if expr, ok := n.(ast.Expr); ok {
+			if _, isFuncLit := expr.(*ast.FuncLit); !isFuncLit {
+				if d := exprDepth(expr); d > depth {
+					depth, pos = d, expr.Pos()
+				}
+			}
+		}
+		return true
+	})
+	return depth, pos
+}
+
+// statementCount returns the number of statements in fn, including those of
+// function literals, and how many of them are control-flow statements: if,
+// for, range, switch and select statements and branches. Blocks and empty
+// statements are not counted: they group or separate statements rather than
+// do anything.
+func statementCount(fn *ast.FuncDecl) (total, control int) {
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.BlockStmt, *ast.EmptyStmt:
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.BranchStmt:
+			total++
+			control++
+		case ast.Stmt:
+			total++
+		}
+		return true
+	})
+	return total, control
+}
+
+// exprDepth measures the nesting of binary, unary, call and parenthesized
+// expressions in the tree rooted at node; other nodes are transparent.
+func exprDepth(node ast.Node) int {
+	if _, ok := node.(*ast.FuncLit); ok {
+		return 0
+	}
+	depth := 0
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == node {
+			return true
+		}
+		if n != nil {
+			depth = max(depth, exprDepth(n))
+		}
+		return false
+	})
+	switch node.(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr, *ast.CallExpr, *ast.ParenExpr:
+		depth++
+	}
+	return depth
+}
+
+// operatorSet returns the distinct operators and control keywords used in
+// fn, sorted. Functions mixing many different constructs tend to be harder
+// to follow than long ones repeating a few.
+func operatorSet(fn *ast.FuncDecl) []string {
+	seen := make(map[token.Token]bool)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BinaryExpr:
+			seen[n.Op] = true
+		case *ast.UnaryExpr:
+			seen[n.Op] = true
+		case *ast.StarExpr:
+			seen[token.MUL] = true
+		case *ast.AssignStmt:
+			seen[n.Tok] = true
+		case *ast.IncDecStmt:
+			seen[n.Tok] = true
+		case *ast.SendStmt:
+			seen[token.ARROW] = true
+		case *ast.BranchStmt:
+			seen[n.Tok] = true
+		case *ast.IfStmt:
+			seen[token.IF] = true
+			if n.Else != nil {
+				seen[token.ELSE] = true
+			}
+		case *ast.ForStmt:
+			seen[token.FOR] = true
+		case *ast.RangeStmt:
+			seen[token.FOR] = true
+			seen[token.RANGE] = true
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt:
+			seen[token.SWITCH] = true
+		case *ast.SelectStmt:
+			seen[token.SELECT] = true
+		case *ast.CaseClause:
+			seen[caseKeyword(n.List == nil)] = true
+		case *ast.CommClause:
+			seen[caseKeyword(n.Comm == nil)] = true
+		case *ast.GoStmt:
+			seen[token.GO] = true
+		case *ast.DeferStmt:
+			seen[token.DEFER] = true
+		case *ast.ReturnStmt:
+			seen[token.RETURN] = true
+		case *ast.FuncLit:
+			seen[token.FUNC] = true
+		}
+		return true
+	})
+	ops := []string{}
+	for tok := range seen {
+		ops = append(ops, tok.String())
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// caseKeyword returns the keyword introducing a case clause.
+func caseKeyword(isDefault bool) token.Token {
+	if isDefault {
+		return token.DEFAULT
+	}
+	return token.CASE
+}
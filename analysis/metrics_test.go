@@ -0,0 +1,229 @@
+package analysis
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestControlVars(t *testing.T) {
+	fset, _, fn := parseSnippet(t, "if x > 0 {\n}\nfor i := 0; i < x; i++ {\n}\nif y != nil {\n}")
+	_, sets := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil)
+	want := map[string]int{"x": 2, "i": 1, "y": 1}
+	if !maps.Equal(sets.control, want) {
+		t.Errorf("control variables %v, want %v", sets.control, want)
+	}
+	var lines []int
+	for _, pos := range sets.controlSites["x"] {
+		lines = append(lines, pos.Line)
+	}
+	slices.Sort(lines)
+	if !slices.Equal(lines, []int{1, 3}) {
+		t.Errorf("x controls the decisions at lines %v, want [1 3]", lines)
+	}
+}
+
+func TestMaxExpressionDepth(t *testing.T) {
+	tests := []struct {
+		name, body string
+		depth      int
+		line       int
+	}{
+		{"no expressions", "var x int\n_ = x", 0, 0},
+		{"identifier", "x := y", 0, 0},
+		{"binary", "x := a + b", 1, 1},
+		{"nested", "x := 1\ny := f(a + (b * -c))", 5, 2},
+		{"function literal", "f := func() int {\n\treturn a + b\n}", 1, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSnippet(t, test.body)
+			depth, pos := maxExpressionDepth(fn)
+			if depth != test.depth {
+				t.Errorf("depth %d, want %d", depth, test.depth)
+			}
+			if depth > 0 && fset.Position(pos).Line != test.line {
+				t.Errorf("deepest expression at line %d, want %d", fset.Position(pos).Line, test.line)
+			}
+		})
+	}
+}
+
+func TestOperatorSet(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"x()", []string{}},
+		{"a := b + c*d", []string{"*", "+", ":="}},
+		{"a += 1\na++\na = -a", []string{"++", "+=", "-", "="}},
+		{"if a > b {\n\treturn\n} else {\n\tch <- *p\n}", []string{"*", "<-", ">", "else", "if", "return"}},
+		{"for range s {\n\tswitch a {\n\tcase 1:\n\t\tbreak\n\tdefault:\n\t}\n}", []string{"break", "case", "default", "for", "range", "switch"}},
+		{"go func() {}()\ndefer f()", []string{"defer", "func", "go"}},
+	}
+	for _, test := range tests {
+		_, _, fn := parseSnippet(t, test.body)
+		if got := operatorSet(fn); !slices.Equal(got, test.want) {
+			t.Errorf("operatorSet(%q) = %v, want %v", test.body, got, test.want)
+		}
+	}
+}
+
+// TestCompoundOperandControlVars checks that the control variables of a
+// comparison are the variables in its operands, not the rendered operands.
+func TestCompoundOperandControlVars(t *testing.T) {
+	tests := []struct {
+		body string
+		want map[string]int
+	}{
+		{"if a+b > c*d {\n}", map[string]int{"a": 1, "b": 1, "c": 1, "d": 1}},
+		{"if f(x) == len(s) {\n}", map[string]int{"x": 1, "s": 1}},
+		{"if p.n > -m {\n}", map[string]int{"p": 1, "m": 1}},
+		{"if (a + a) > 0 {\n}", map[string]int{"a": 1}},
+	}
+	for _, test := range tests {
+		fset, _, fn := parseSnippet(t, test.body)
+		_, sets := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil)
+		if !maps.Equal(sets.control, test.want) {
+			t.Errorf("control variables of %q are %v, want %v", test.body, sets.control, test.want)
+		}
+	}
+}
+
+func TestChepinWeights(t *testing.T) {
+	sets := &chepinSets{
+		input:    map[string]int{"a": 1, "b": 1},
+		modified: map[string]bool{"c": true},
+		control:  map[string]int{"a": 2},
+		unused:   map[string]bool{"d": true, "e": true},
+	}
+	tests := []struct {
+		p, m, c, w float64
+		want       float64
+		custom     bool
+	}{
+		{1, 2, 3, 0.5, 2 + 2 + 3 + 1, false},
+		{0, 0, 1, 0, 1, true},
+		{2, 1, 1, 1, 4 + 1 + 1 + 2, true},
+	}
+	for _, test := range tests {
+		a := &analyzer{opts: Options{ChepinP: test.p, ChepinM: test.m, ChepinC: test.c, ChepinT: test.w}}
+		if got := a.chepinScore(sets); got != test.want {
+			t.Errorf("score with weights %g/%g/%g/%g = %g, want %g", test.p, test.m, test.c, test.w, got, test.want)
+		}
+		if got := a.customChepinWeights(); got != test.custom {
+			t.Errorf("customChepinWeights() with %g/%g/%g/%g = %v, want %v", test.p, test.m, test.c, test.w, got, test.custom)
+		}
+	}
+}
+
+// TestVocabulary counts the variables the data flow tracks: those defined,
+// modified or tested in conditions. Plain uses on the right-hand side of an
+// assignment have no data-flow node and are not counted.
+func TestVocabulary(t *testing.T) {
+	tests := []struct {
+		name, src string
+		want      int
+	}{
+		{"sample", SampleSource, 9},
+		{"no variables", SnippetSource("f()"), 0},
+		{"assignment", SnippetSource("x := a + b"), 1},
+		{"condition only", SnippetSource("if a > b {\n}"), 2},
+		{"redefinitions", SnippetSource("x := 1\nx = x + 1\nx++"), 1},
+		{"loop", SnippetSource("for i := 0; i < n; i++ {\n\ts += i\n}"), 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, _, fn := parseSource(t, test.src)
+			if _, sets := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil); sets.vocabulary != test.want {
+				t.Errorf("vocabulary %d, want %d", sets.vocabulary, test.want)
+			}
+		})
+	}
+}
+
+func TestStatementCount(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"empty", "", 0},
+		{"straight line", "x := 1\nx++\nf(x)", 3},
+		{"if with else", "if x > 0 {\n\tf()\n} else {\n\tg()\n}", 3},
+		{"loop", "for i := 0; i < n; i++ {\n\tf(i)\n}", 4}, // for, init, post, body
+		{"empty statements", "f();;", 1},
+		{"function literal", "g := func() {\n\tf()\n\treturn\n}", 3},
+		{"labeled", "L:\n\tfor {\n\t\tbreak L\n\t}", 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fset, file, fn := parseSnippet(t, test.body)
+			total, _ := statementCount(fn)
+			if total != test.want {
+				t.Errorf("%d statements, want %d", total, test.want)
+			}
+			// The finding is reported above the threshold only.
+			for _, threshold := range []int{test.want - 1, test.want} {
+				a := &analyzer{opts: Options{MaxStatements: threshold}}
+				reported := slices.ContainsFunc(a.checkFunc(fset, file, fn, newCFG(fn)), func(f Finding) bool {
+					return f.RuleID == "too-many-statements"
+				})
+				if want := threshold > 0 && test.want > threshold; reported != want {
+					t.Errorf("-max-statements %d: reported = %v, want %v", threshold, reported, want)
+				}
+			}
+		})
+	}
+}
+
+func TestControlFlowRatio(t *testing.T) {
+	tests := []struct {
+		name, body     string
+		total, control int
+		ratio          float64
+	}{
+		{"empty", "", 0, 0, 0},
+		{"straight line", "x := 1\nf(x)", 2, 0, 0},
+		{"if", "if x > 0 {\n\tf()\n}", 2, 1, 0.5},
+		{"loop with break", "for {\n\tif x > 0 {\n\t\tbreak\n\t}\n\tf()\n}", 4, 3, 0.75},
+		{"range and switch", "for _, v := range xs {\n\tswitch v {\n\tcase 1:\n\t\tf()\n\t}\n}", 4, 2, 0.5},
+		// Case clauses are statements; the receive of the clause is one too.
+		{"select", "select {\ncase <-ch:\n\tf()\n}", 4, 1, 0.25},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, fn := parseSnippet(t, test.body)
+			if total, control := statementCount(fn); total != test.total || control != test.control {
+				t.Errorf("statementCount = %d, %d, want %d, %d", total, control, test.total, test.control)
+			}
+			results, err := AnalyzeSource(context.Background(), "snippet.go", SnippetSource(test.body), DefaultOptions())
+			if err != nil || len(results) != 1 {
+				t.Fatalf("AnalyzeSource: %d results, error %v", len(results), err)
+			}
+			if got := results[0].Metrics.ControlFlowRatio; got != test.ratio {
+				t.Errorf("control-flow ratio %g, want %g", got, test.ratio)
+			}
+		})
+	}
+}
+
+func TestBareOperandControlVars(t *testing.T) {
+	tests := []struct {
+		body string
+		want map[string]int
+	}{
+		{"if ok {\n}", map[string]int{"ok": 1}},
+		{"if !done {\n}", map[string]int{"done": 1}},
+		{"for *p {\n}", map[string]int{"p": 1}},
+		{"switch m[k] {\ncase 1:\n}", map[string]int{"m": 1, "k": 1}},
+		{"if (a > b) {\n}", map[string]int{"a": 1, "b": 1}},
+		{"for (i < n) {\n}", map[string]int{"i": 1, "n": 1}},
+	}
+	for _, test := range tests {
+		fset, _, fn := parseSnippet(t, test.body)
+		if _, sets := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil); !maps.Equal(sets.control, test.want) {
+			t.Errorf("%q: control variables %v, want %v", test.body, sets.control, test.want)
+		}
+	}
+}
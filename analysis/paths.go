@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// maxPathLength bounds a single basis path so that loops without an exit
+// cannot make the walk run forever.
+const maxPathLength = 1000
+
+// basisPaths enumerates a basis set of independent paths through cg using
+// McCabe's baseline method. The baseline follows the first successor of
+// every block; then every decision block, at its first appearance on a path,
+// is flipped once to each of its other successors, and the new path
+// continues by the same rule. A path never takes the same edge twice while
+// an untaken one is available, so loops are traversed at most once. Each
+// path is returned as the list of visited block indices. When ctx is done
+// the paths found so far are returned.
+func basisPaths(ctx context.Context, cg *cfg.CFG) [][]int32 {
+	if len(cg.Blocks) == 0 {
+		return nil
+	}
+	paths := [][]int32{followPath(nil, cg.Blocks[0])}
+	flipped := make(map[int32]bool)
+	for p := 0; p < len(paths) && ctx.Err() == nil; p++ {
+		path := paths[p]
+		for i, index := range path {
+			block := cg.Blocks[index]
+			if len(block.Succs) < 2 || flipped[index] || i+1 >= len(path) {
+				continue
+			}
+			flipped[index] = true
+			for _, succ := range block.Succs {
+				if succ.Index == path[i+1] {
+					continue
+				}
+				prefix := append([]int32{}, path[:i+1]...)
+				paths = append(paths, followPath(prefix, succ))
+			}
+		}
+	}
+	return paths
+}
+
+// followPath extends prefix with block and then walks successors until a
+// block without successors is reached, preferring the first successor whose
+// edge the path has not taken yet.
+func followPath(prefix []int32, block *cfg.Block) []int32 {
+	type edge struct{ from, to int32 }
+	taken := make(map[edge]bool)
+	for i := 1; i < len(prefix); i++ {
+		taken[edge{prefix[i-1], prefix[i]}] = true
+	}
+	if len(prefix) > 0 {
+		taken[edge{prefix[len(prefix)-1], block.Index}] = true
+	}
+
+	path := append(prefix, block.Index)
+	for len(block.Succs) > 0 && len(path) < maxPathLength {
+		next := block.Succs[0]
+		for _, succ := range block.Succs {
+			if !taken[edge{block.Index, succ.Index}] {
+				next = succ
+				break
+			}
+		}
+		if taken[edge{block.Index, next.Index}] {
+			// Every way out has been taken: the path is stuck in a loop
+			// without exit.
+			break
+		}
+		taken[edge{block.Index, next.Index}] = true
+		path = append(path, next.Index)
+		block = next
+	}
+	return path
+}
+
+func printBasisPaths(ctx context.Context, w io.Writer, cg *cfg.CFG) {
+	paths := basisPaths(ctx, cg)
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintf(w, "Basis Paths: %d.\n", len(paths))
+	// A loop without exit can stop the walk before every decision is flipped.
+	if complexity, _, _ := cyclomatic(cg); complexity != len(paths) {
+		fmt.Fprintf(w, "  (cyclomatic complexity is %d)\n", complexity)
+	}
+	for i, path := range paths {
+		blocks := []string{}
+		for _, index := range path {
+			blocks = append(blocks, strconv.Itoa(int(index)))
+		}
+		fmt.Fprintf(w, "  %d: %s\n", i+1, strings.Join(blocks, " -> "))
+	}
+}
@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"math"
+	"testing"
+)
+
+func TestBasisPaths(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"straight line", "x()", 1},
+		{"if", "if a > b {\n\tx()\n}", 2},
+		{"if else", "if a > b {\n\tx()\n} else {\n\ty()\n}", 2},
+		{"loop", "for i := 0; i < n; i++ {\n\tx()\n}", 2},
+		{"loop with branch", "for i := 0; i < n; i++ {\n\tif i > 2 {\n\t\tx()\n\t}\n}", 3},
+		{"else if chain", "if a > 0 {\n\tx()\n} else if a < 0 {\n\ty()\n} else {\n\tz()\n}", 3},
+		{"two returns", "if a > 0 {\n\treturn\n}\nx()", 2},
+		{"sample", "", 8},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var fn *ast.FuncDecl
+			if test.body == "" {
+				_, _, fn = parseSource(t, SampleSource)
+			} else {
+				_, _, fn = parseSnippet(t, test.body)
+			}
+			cg := newCFG(fn)
+			paths := basisPaths(context.Background(), cg)
+			if len(paths) != test.want {
+				t.Fatalf("%d paths %v, want %d", len(paths), paths, test.want)
+			}
+			if complexity, _, _ := cyclomatic(cg); complexity != len(paths) {
+				t.Errorf("%d paths, but cyclomatic complexity %d", len(paths), complexity)
+			}
+			seen := make(map[string]bool)
+			for _, path := range paths {
+				if path[0] != 0 || len(cg.Blocks[path[len(path)-1]].Succs) != 0 {
+					t.Errorf("path %v does not lead from the entry to an exit", path)
+				}
+				if key := fmt.Sprint(path); seen[key] {
+					t.Errorf("path %v appears twice", path)
+				} else {
+					seen[key] = true
+				}
+			}
+			if rank := edgeRank(paths); rank != len(paths) {
+				t.Errorf("only %d of the %d paths are linearly independent", rank, len(paths))
+			}
+		})
+	}
+}
+
+// edgeRank returns the rank of the edge count vectors of paths. Every path
+// also takes a virtual edge into the entry, so that a path without edges
+// counts as well.
+func edgeRank(paths [][]int32) int {
+	type edge struct{ from, to int32 }
+	columns := make(map[edge]int)
+	var rows [][]float64
+	for _, path := range paths {
+		row := make([]float64, len(columns))
+		path = append([]int32{-1}, path...)
+		for i := 1; i < len(path); i++ {
+			e := edge{path[i-1], path[i]}
+			if _, ok := columns[e]; !ok {
+				columns[e] = len(columns)
+				row = append(row, 0)
+			}
+			row[columns[e]]++
+		}
+		rows = append(rows, row)
+	}
+	for i := range rows {
+		rows[i] = append(rows[i], make([]float64, len(columns)-len(rows[i]))...)
+	}
+	rank := 0
+	for col := 0; col < len(columns) && rank < len(rows); col++ {
+		pivot := -1
+		for r := rank; r < len(rows); r++ {
+			if math.Abs(rows[r][col]) > 1e-9 {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		rows[rank], rows[pivot] = rows[pivot], rows[rank]
+		for r := range rows {
+			if r != rank && rows[r][col] != 0 {
+				f := rows[r][col] / rows[rank][col]
+				for c := range rows[r] {
+					rows[r][c] -= f * rows[rank][c]
+				}
+			}
+		}
+		rank++
+	}
+	return rank
+}
@@ -0,0 +1,99 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// Static branch probabilities used by -probabilities.
+const (
+	likely   = 0.9
+	unlikely = 0.1
+)
+
+// branchProbability estimates how often control flows from block to succ.
+// The estimate is a crude static heuristic:
+//
+//   - an edge out of a block with a single successor is always taken;
+//   - a branch leading to an error exit (a return of an err value, an
+//     errors.New or fmt.Errorf result, or a call to panic, os.Exit or
+//     log.Fatal*) is unlikely, and the other branch likely;
+//   - entering a loop body is likely and leaving the loop unlikely, so
+//     that loop back-edges carry the weight of the body;
+//   - any other two-way branch is taken half of the time.
+func branchProbability(cg *cfg.CFG, block, succ *cfg.Block) float64 {
+	if len(block.Succs) < 2 {
+		return 1
+	}
+	var errorExits int
+	for _, s := range block.Succs {
+		if exitsWithError(cg, s) {
+			errorExits++
+		}
+	}
+	if errorExits == 1 {
+		if exitsWithError(cg, succ) {
+			return unlikely
+		}
+		return likely
+	}
+	switch succ.Kind {
+	case cfg.KindForBody, cfg.KindRangeBody:
+		return likely
+	case cfg.KindForDone, cfg.KindRangeDone:
+		return unlikely
+	}
+	return 1 / float64(len(block.Succs))
+}
+
+// exitsWithError reports whether the first block with nodes reached from
+// block ends the function with an error.
+func exitsWithError(cg *cfg.CFG, block *cfg.Block) bool {
+	next := findNextBlockWithNodes(cg, int(block.Index))
+	if next == nil {
+		return false
+	}
+	for _, node := range next.Nodes {
+		switch n := node.(type) {
+		case *ast.ReturnStmt:
+			if len(n.Results) > 0 && isErrorValue(n.Results[len(n.Results)-1]) {
+				return true
+			}
+		case *ast.ExprStmt:
+			if call, ok := n.X.(*ast.CallExpr); ok {
+				switch name := types.ExprString(call.Fun); {
+				case name == "panic", name == "os.Exit", strings.HasPrefix(name, "log.Fatal"):
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isErrorValue reports whether expr looks like a non-nil error by name.
+func isErrorValue(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name == "err" || strings.HasSuffix(e.Name, "Err")
+	case *ast.CallExpr:
+		name := types.ExprString(e.Fun)
+		return name == "errors.New" || name == "fmt.Errorf"
+	}
+	return false
+}
+
+// probabilityAttrs returns the DOT attributes drawing the edge from block to
+// succ with a width and label matching its estimated probability, or "" if
+// Options.Probabilities is not set.
+func (a *analyzer) probabilityAttrs(cg *cfg.CFG, block, succ *cfg.Block) string {
+	if !a.opts.Probabilities {
+		return ""
+	}
+	p := branchProbability(cg, block, succ)
+	return fmt.Sprintf(" penwidth=%.1f xlabel=\"%.0f%%\"", 1+4*p, 100*p)
+}
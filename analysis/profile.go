@@ -0,0 +1,19 @@
+package analysis
+
+import "time"
+
+// Phases lists the analysis phases timed in a Profile, in reporting order.
+var Phases = []string{"parse", "cfg", "dot", "metrics"}
+
+// Profile accumulates the time spent in each phase across all functions,
+// keyed by phase.
+type Profile map[string]time.Duration
+
+// timePhase adds the time elapsed since start to phase, if the options
+// collect a profile. It is meant to be deferred or called right after the
+// timed work.
+func (a *analyzer) timePhase(phase string, start time.Time) {
+	if a.opts.Profile != nil {
+		a.opts.Profile[phase] += time.Since(start)
+	}
+}
@@ -0,0 +1,132 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// rangeHeader is the X, Key or Value expression of a range statement where
+// it appears as a CFG node: go/cfg evaluates them, in that order, in the
+// block that enters the loop.
+type rangeHeader struct {
+	stmt *ast.RangeStmt
+	part ast.Expr
+}
+
+func (h *rangeHeader) Pos() token.Pos { return h.part.Pos() }
+func (h *rangeHeader) End() token.Pos { return h.part.End() }
+
+// asRangeHeader returns node wrapped in a *rangeHeader if it is part of the
+// header of a range loop entered from block, and node itself otherwise.
+func asRangeHeader(block *cfg.Block, node ast.Node) ast.Node {
+	for _, succ := range block.Succs {
+		rangeStmt, ok := succ.Stmt.(*ast.RangeStmt)
+		if !ok || succ.Kind != cfg.KindRangeLoop {
+			continue
+		}
+		for _, part := range []ast.Expr{rangeStmt.X, rangeStmt.Key, rangeStmt.Value} {
+			if part != nil && node == ast.Node(part) {
+				return &rangeHeader{stmt: rangeStmt, part: part}
+			}
+		}
+	}
+	return node
+}
+
+// rangeLoopOf returns the loop block of the range statement whose header
+// ends block, or nil if block does not end in a range header. The loop block
+// has no nodes; it branches to the body and to the code after the loop.
+func rangeLoopOf(block *cfg.Block) *cfg.Block {
+	if len(block.Nodes) == 0 {
+		return nil
+	}
+	header, ok := asRangeHeader(block, block.Nodes[len(block.Nodes)-1]).(*rangeHeader)
+	if !ok {
+		return nil
+	}
+	for _, succ := range block.Succs {
+		if succ.Kind == cfg.KindRangeLoop && succ.Stmt == header.stmt {
+			return succ
+		}
+	}
+	return nil
+}
+
+// rangeHeadID returns the ID of the node that every iteration of the range
+// loop with loop block loop starts from: the assignment of the key, or the
+// range clause itself if the loop assigns nothing. It returns "" if the
+// node is not in cg.
+func rangeHeadID(cg *cfg.CFG, loop *cfg.Block) string {
+	rangeStmt := loop.Stmt.(*ast.RangeStmt)
+	head := ast.Node(rangeStmt.X)
+	if rangeStmt.Key != nil {
+		head = rangeStmt.Key
+	}
+	for _, block := range cg.Blocks {
+		for i, node := range block.Nodes {
+			if node == head {
+				return fmt.Sprintf("block_%d_node_%d", block.Index, i)
+			}
+		}
+	}
+	return ""
+}
+
+// rangesOverInt reports whether rangeStmt iterates over an integer, as in
+// Go 1.22's "for i := range n". Without type information only integer
+// literals and len and cap calls are recognized.
+func rangesOverInt(rangeStmt *ast.RangeStmt, info *types.Info) bool {
+	if info != nil {
+		if typ := info.TypeOf(rangeStmt.X); typ != nil {
+			basic, ok := typ.Underlying().(*types.Basic)
+			return ok && basic.Info()&types.IsInteger != 0
+		}
+	}
+	switch x := ast.Unparen(rangeStmt.X).(type) {
+	case *ast.BasicLit:
+		return x.Kind == token.INT
+	case *ast.CallExpr:
+		if fun, ok := x.Fun.(*ast.Ident); ok {
+			return fun.Name == "len" || fun.Name == "cap"
+		}
+	}
+	return false
+}
+
+// rangeLabel returns the label of the range header part h: the whole loop
+// clause for X, and what the loop assigns for Key and Value, e.g.
+// "i in [0, n)" when ranging over an integer n. Assignments to the blank
+// identifier are shown as discarded.
+func (a *analyzer) rangeLabel(h *rangeHeader, overInt bool) string {
+	s := h.stmt
+	x := a.getValue(s.X)
+	switch h.part {
+	case s.X:
+		clause := "for range " + x
+		if s.Key != nil {
+			vars := a.getValue(s.Key)
+			if s.Value != nil {
+				vars += ", " + a.getValue(s.Value)
+			}
+			clause = fmt.Sprintf("for %s %s range %s", vars, s.Tok, x)
+		}
+		return clause
+	case s.Key:
+		if a.getValue(s.Key) == "_" {
+			return "key of " + x + " discarded"
+		}
+		if overInt {
+			return fmt.Sprintf("%s in [0, %s)", a.getValue(s.Key), x)
+		}
+		return fmt.Sprintf("%s = key of %s", a.getValue(s.Key), x)
+	default:
+		if a.getValue(s.Value) == "_" {
+			return "value of " + x + " discarded"
+		}
+		return fmt.Sprintf("%s = value of %s", a.getValue(s.Value), x)
+	}
+}
@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"go/ast"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRangesOverInt(t *testing.T) {
+	tests := []struct {
+		clause string
+		want   bool
+	}{
+		{"for i := range 10", true},
+		{"for i := range len(s)", true},
+		{"for range cap(s)", true},
+		{"for i := range (n)", false},
+		{"for i := range s", false},
+		{"for k, v := range m", false},
+		{`for i := range "abc"`, false},
+	}
+	for _, test := range tests {
+		_, _, fn := parseSnippet(t, test.clause+" {\n}")
+		if got := rangesOverInt(firstOf[*ast.RangeStmt](t, fn), nil); got != test.want {
+			t.Errorf("rangesOverInt(%s) = %v, want %v", test.clause, got, test.want)
+		}
+	}
+}
+
+func TestRangeLoopEdges(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []dotEdge // without attributes
+	}{
+		{"integer", "for i := range 3 {\n\tz(i)\n}\nw()", []dotEdge{
+			{"for i := range 3", "i in [0, 3)", ""},
+			{"i in [0, 3)", "z(i)", "(RangeBody)"},
+			{"i in [0, 3)", "w()", "(RangeDone)"},
+			{"z(i)", "i in [0, 3)", ""},
+			{"w()", "Return: ", ""},
+		}},
+		{"key and value", "for _, v := range s {\n\tz(v)\n}", []dotEdge{
+			{"for _, v := range s", "key of s discarded", ""},
+			{"key of s discarded", "v = value of s", ""},
+			{"v = value of s", "z(v)", "(RangeBody)"},
+			{"v = value of s", "Return: ", "(RangeDone)"},
+			{"z(v)", "key of s discarded", ""},
+		}},
+		{"empty body", "for range s {\n}\nw()", []dotEdge{
+			{"for range s", "for range s", "(RangeBody)"},
+			{"for range s", "w()", "(RangeDone)"},
+			{"w()", "Return: ", ""},
+		}},
+		{"continue", "for i := range 3 {\n\tif i > 1 {\n\t\tcontinue\n\t}\n\tz(i)\n}", []dotEdge{
+			{"for i := range 3", "i in [0, 3)", ""},
+			{"i in [0, 3)", "i > 1", "(RangeBody)"},
+			{"i in [0, 3)", "Return: ", "(RangeDone)"},
+			{"i > 1", "z(i)", "(IfDone)"},
+			{"i > 1", "i in [0, 3)", "continue"},
+			{"z(i)", "i in [0, 3)", ""},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dot := snippetDot(t, DefaultOptions(), test.body)
+			var got []dotEdge
+			for _, edge := range dotEdges(dot) {
+				label := ""
+				if _, rest, ok := strings.Cut(edge.attrs, `label="`); ok {
+					label, _, _ = strings.Cut(rest, `"`)
+				}
+				got = append(got, dotEdge{edge.from, edge.to, label})
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("edges %v, want %v\n%s", got, test.want, dot)
+			}
+		})
+	}
+}
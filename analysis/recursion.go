@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// recursiveCalls returns the calls in fn to fn itself. With type information
+// the callee is resolved exactly; without it a call matches by name: a plain
+// call f(...) in a function f, or recv.m(...) in a method m with receiver
+// recv.
+func recursiveCalls(fn *ast.FuncDecl, info *types.Info) []*ast.CallExpr {
+	var self types.Object
+	if info != nil {
+		self = info.Defs[fn.Name]
+	}
+	recv := ""
+	if fn.Recv != nil && len(fn.Recv.List) > 0 && len(fn.Recv.List[0].Names) > 0 {
+		recv = fn.Recv.List[0].Names[0].Name
+	}
+	var calls []*ast.CallExpr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if self != nil {
+			if typeutil.Callee(info, call) == self {
+				calls = append(calls, call)
+			}
+			return true
+		}
+		switch f := call.Fun.(type) {
+		case *ast.Ident:
+			if fn.Recv == nil && f.Name == fn.Name.Name {
+				calls = append(calls, call)
+			}
+		case *ast.SelectorExpr:
+			if x, ok := f.X.(*ast.Ident); ok && recv != "" && x.Name == recv && f.Sel.Name == fn.Name.Name {
+				calls = append(calls, call)
+			}
+		}
+		return true
+	})
+	return calls
+}
+
+// containsCall reports whether node contains one of calls.
+func containsCall(node ast.Node, calls []*ast.CallExpr) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		for _, call := range calls {
+			if n == ast.Node(call) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+func printRecursion(w io.Writer, fset *token.FileSet, calls []*ast.CallExpr) {
+	if len(calls) == 0 {
+		return
+	}
+	lines := []string{}
+	for _, call := range calls {
+		lines = append(lines, fmt.Sprint(fset.Position(call.Pos()).Line))
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintf(w, "Recursion detected: %d recursive calls (lines %s).\n", len(calls), strings.Join(lines, ", "))
+}
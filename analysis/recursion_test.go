@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRecursiveCalls(t *testing.T) {
+	tests := []struct {
+		name, src string
+		want      int
+	}{
+		{"function", "func fact(n int) int {\n\tif n <= 1 {\n\t\treturn 1\n\t}\n\treturn n * fact(n-1)\n}", 1},
+		{"method", "func (t *T) walk() {\n\tt.left.walk()\n\tt.walk()\n}", 1},
+		{"other function", "func f() {\n\tg()\n}", 0},
+		{"same name other receiver", "func (t *T) walk() {\n\tu.walk()\n}", 0},
+		{"function named like a method", "func walk() {\n\tt.walk()\n}", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, fn := parseSource(t, "package p\n\n"+test.src+"\n")
+			if got := len(recursiveCalls(fn, nil)); got != test.want {
+				t.Errorf("%d recursive calls, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecursionEdge(t *testing.T) {
+	fset, _, fn := parseSource(t, "package p\n\nfunc fact(n int) int {\n\tr := 1\n\tif n > 1 {\n\t\tr = n * fact(n-1)\n\t}\n\treturn r\n}\n")
+	dot, _ := defaultAnalyzer().genDot(fset, fn, newCFG(fn), nil)
+	var recursion []dotEdge
+	for _, edge := range dotEdges(dot) {
+		if strings.Contains(edge.attrs, `label="recursion"`) {
+			recursion = append(recursion, dotEdge{edge.from, edge.to, ""})
+		}
+	}
+	if want := []dotEdge{{"r = n * fact(n - 1)", "r = 1", ""}}; !slices.Equal(recursion, want) {
+		t.Errorf("recursion edges %v, want %v", recursion, want)
+	}
+}
+
+func TestRecursionNodeStyle(t *testing.T) {
+	tests := []struct {
+		scheme, want string
+	}{
+		{"default", `[style="dashed" color="purple"];`},
+		{"grayscale", `[style="filled,dashed" color="#707070"];`},
+		{"colorblind-safe", `[style="filled,dashed" color="#cc79a7"];`},
+	}
+	for _, test := range tests {
+		t.Run(test.scheme, func(t *testing.T) {
+			fset, _, fn := parseSource(t, "package p\n\nfunc loop() {\n\tloop()\n}\n")
+			a := &analyzer{opts: Options{ColorScheme: test.scheme}}
+			dot, _ := a.genDot(fset, fn, newCFG(fn), nil)
+			if !strings.Contains(dot, "  block_0_node_0 "+test.want) {
+				t.Errorf("recursive call node not styled %s:\n%s", test.want, dot)
+			}
+		})
+	}
+}
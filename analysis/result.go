@@ -0,0 +1,138 @@
+package analysis
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// Result is the outcome of analyzing one function.
+type Result struct {
+	Metrics     Metrics
+	Findings    []Finding
+	Dot         string            // the DOT graph of the function
+	Callees     map[string]int    // calls by target name, see calleeNames
+	AST         *ASTNode          // the syntax tree, with Options.AST only
+	ASCII       string            // the ASCII rendering, with Options.ASCII only
+	SplitGraphs map[string]string // the graphs by file name, with Options.Split only; see splitDot
+}
+
+// Metrics are the metrics computed for one function. The JSON field names
+// are part of the -format metrics-json output and must stay stable.
+type Metrics struct {
+	Kind             string          `json:"kind"` // always "function"
+	Name             string          `json:"name"`
+	File             string          `json:"file"`
+	Line             int             `json:"line"`
+	Lines            int             `json:"lines"`
+	Statements       int             `json:"statements"`
+	ControlFlowRatio float64         `json:"control_flow_ratio"`
+	Cyclomatic       int             `json:"cyclomatic"`
+	ASTCyclomatic    int             `json:"ast_cyclomatic"`
+	Cognitive        int             `json:"cognitive"`
+	ChepinP          int             `json:"chepin_p"`
+	ChepinM          int             `json:"chepin_m"`
+	ChepinC          int             `json:"chepin_c"`
+	ChepinT          int             `json:"chepin_t"`
+	Chepin           float64         `json:"chepin"`
+	MaxExprDepth     int             `json:"max_expr_depth"`
+	Operators        []string        `json:"operators"`
+	DistinctOps      int             `json:"distinct_operators"`
+	Vocabulary       int             `json:"variables"`
+	BasisPaths       int             `json:"basis_paths"`
+	Components       int             `json:"sccs"`
+	LoopComponents   []int           `json:"loop_scc_sizes"`
+	GotoLoops        int             `json:"goto_loops"`
+	IrreducibleLoops int             `json:"irreducible_loops"`
+	LargestBlock     int             `json:"largest_block"`
+	AverageBlockSize float64         `json:"average_block_size"`
+	EmptyBlocks      int             `json:"empty_blocks"`
+	Calls            map[string]int  `json:"calls"`
+	Conversions      int             `json:"conversions"`
+	RecursiveCalls   int             `json:"recursive_calls"`
+	Dominators       map[int32]int32 `json:"immediate_dominators,omitempty"` // with Options.Dominators
+}
+
+// computeMetrics gathers the metrics of fn from its CFG and the Chepin sets
+// collected by genDot.
+func (a *analyzer) computeMetrics(ctx context.Context, fset *token.FileSet, file *ast.File, fn *ast.FuncDecl, cg *cfg.CFG, info *types.Info, chepin *chepinSets) Metrics {
+	start := fset.Position(fn.Pos())
+	metrics := Metrics{
+		Kind:           "function",
+		Name:           funcName(fn),
+		File:           start.Filename,
+		Line:           start.Line,
+		Lines:          fset.Position(fn.End()).Line - start.Line + 1,
+		Operators:      []string{},
+		LoopComponents: []int{},
+		Calls:          map[string]int{},
+	}
+	// The CFG metrics are accumulated by visitors in a single walk, together
+	// with those of the caller.
+	var mccabe cyclomaticCounter
+	sizes := newBlockSizeCounter()
+	var visitors []NodeVisitor
+	if a.wantMetric("cyclomatic") {
+		visitors = append(visitors, mccabe.visit)
+	}
+	if a.wantMetric("blocks") {
+		visitors = append(visitors, sizes.visit)
+	}
+	walkCFG(cg, append(visitors, a.opts.Visitors...)...)
+
+	if a.wantMetric("cyclomatic") {
+		metrics.Cyclomatic = mccabe.complexity()
+		metrics.ASTCyclomatic = astCyclomatic(fn)
+	}
+	if a.wantMetric("cognitive") {
+		metrics.Cognitive = cognitiveComplexity(fn)
+	}
+	if a.wantMetric("chepin") {
+		metrics.ChepinP = len(chepin.input)
+		metrics.ChepinM = len(chepin.modified)
+		metrics.ChepinC = len(chepin.control)
+		metrics.ChepinT = len(chepin.unused)
+		metrics.Chepin = a.chepinScore(chepin)
+	}
+	if a.wantMetric("statements") {
+		var control int
+		metrics.Statements, control = statementCount(fn)
+		if metrics.Statements > 0 {
+			metrics.ControlFlowRatio = float64(control) / float64(metrics.Statements)
+		}
+	}
+	if a.wantMetric("depth") {
+		metrics.MaxExprDepth, _ = maxExpressionDepth(fn)
+	}
+	if a.wantMetric("operators") {
+		metrics.Operators = operatorSet(fn)
+		metrics.DistinctOps = len(metrics.Operators)
+	}
+	if a.wantMetric("vocabulary") {
+		metrics.Vocabulary = chepin.vocabulary
+	}
+	if a.wantMetric("paths") {
+		metrics.BasisPaths = len(basisPaths(ctx, cg))
+	}
+	if a.wantMetric("sccs") {
+		metrics.Components = len(stronglyConnected(cg))
+		metrics.LoopComponents = loopComponents(cg)
+		metrics.GotoLoops, metrics.IrreducibleLoops = unstructuredLoops(cg)
+	}
+	if a.wantMetric("blocks") {
+		metrics.LargestBlock, metrics.AverageBlockSize, metrics.EmptyBlocks = sizes.stats()
+	}
+	if a.wantMetric("calls") {
+		metrics.Calls, metrics.Conversions = classifyCalls(file, fn, info)
+	}
+	if a.wantMetric("recursion") {
+		metrics.RecursiveCalls = len(recursiveCalls(fn, info))
+	}
+	if a.opts.Dominators {
+		metrics.Dominators = dominators(cg)
+	}
+	return metrics
+}
@@ -0,0 +1,102 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// stronglyConnected returns the strongly connected components of the live
+// blocks of cg, found with Tarjan's algorithm. Each component is the sorted
+// list of its block indices; components are ordered by their first block.
+func stronglyConnected(cg *cfg.CFG) [][]int32 {
+	index := make(map[int32]int)
+	lowlink := make(map[int32]int)
+	onStack := make(map[int32]bool)
+	var stack []int32
+	var components [][]int32
+
+	var connect func(block *cfg.Block)
+	connect = func(block *cfg.Block) {
+		index[block.Index] = len(index)
+		lowlink[block.Index] = index[block.Index]
+		stack = append(stack, block.Index)
+		onStack[block.Index] = true
+		for _, succ := range block.Succs {
+			if _, visited := index[succ.Index]; !visited {
+				connect(succ)
+				lowlink[block.Index] = min(lowlink[block.Index], lowlink[succ.Index])
+			} else if onStack[succ.Index] {
+				lowlink[block.Index] = min(lowlink[block.Index], index[succ.Index])
+			}
+		}
+		if lowlink[block.Index] != index[block.Index] {
+			return
+		}
+		var component []int32
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == block.Index {
+				break
+			}
+		}
+		sort.Slice(component, func(i, j int) bool { return component[i] < component[j] })
+		components = append(components, component)
+	}
+	for _, block := range cg.Blocks {
+		if _, visited := index[block.Index]; block.Live && !visited {
+			connect(block)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i][0] < components[j][0] })
+	return components
+}
+
+// loopComponents returns the sizes of the components of cg that contain a
+// cycle: those of more than one block, and single blocks looping to
+// themselves. Several of them, or one much larger than the loops in the
+// source, hint at nested or irreducible loops.
+func loopComponents(cg *cfg.CFG) []int {
+	sizes := []int{}
+	for _, component := range stronglyConnected(cg) {
+		if len(component) > 1 || succOfIndex(cg.Blocks[component[0]], component[0]) {
+			sizes = append(sizes, len(component))
+		}
+	}
+	return sizes
+}
+
+// succOfIndex reports whether block has a successor with the given index.
+func succOfIndex(block *cfg.Block, index int32) bool {
+	for _, succ := range block.Succs {
+		if succ.Index == index {
+			return true
+		}
+	}
+	return false
+}
+
+func printComponents(w io.Writer, cg *cfg.CFG) {
+	components := stronglyConnected(cg)
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintf(w, "Strongly Connected Components: %d.\n", len(components))
+	for _, component := range components {
+		if len(component) == 1 && !succOfIndex(cg.Blocks[component[0]], component[0]) {
+			continue
+		}
+		blocks := []string{}
+		for _, index := range component {
+			blocks = append(blocks, fmt.Sprint(index))
+		}
+		fmt.Fprintf(w, "  loop: blocks %s\n", strings.Join(blocks, ", "))
+	}
+	if gotoLoops, irreducible := unstructuredLoops(cg); gotoLoops+irreducible > 0 {
+		fmt.Fprintf(w, "Unstructured loops: %d goto, %d irreducible.\n", gotoLoops, irreducible)
+	}
+}
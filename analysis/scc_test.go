@@ -0,0 +1,41 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoopComponents(t *testing.T) {
+	tests := []struct {
+		name, body string
+		want       []int
+	}{
+		{"no loop", "if a > b {\n\tx()\n}", nil},
+		{"self loop", "for {\n\tx()\n}", []int{1}},
+		{"for loop", "for i := 0; i < 3; i++ {\n\tx()\n}", []int{3}},
+		{"two loops", "for i < 3 {\n\tx()\n}\nfor j < 3 {\n\ty()\n}", []int{2, 2}},
+		{"goto loop", "L:\n\tx()\n\tif a > b {\n\t\tgoto L\n\t}", []int{2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, fn := parseSnippet(t, test.body)
+			cg := newCFG(fn)
+			if got := loopComponents(cg); !slices.Equal(got, test.want) {
+				t.Errorf("loop components %v, want %v", got, test.want)
+			}
+			live := 0
+			for _, block := range cg.Blocks {
+				if block.Live {
+					live++
+				}
+			}
+			size := 0
+			for _, component := range stronglyConnected(cg) {
+				size += len(component)
+			}
+			if size != live {
+				t.Errorf("components cover %d blocks, want the %d live ones", size, live)
+			}
+		})
+	}
+}
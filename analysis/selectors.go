@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// selectorNames counts the names of the selector chain ending in e, e.g. 5
+// for a.b.c.d.e, and returns the expression the chain starts from. A call or
+// index expression ends the chain and counts as one name, so f().b.c has 3.
+func selectorNames(e *ast.SelectorExpr) (root ast.Expr, names int) {
+	names = 2
+	for root = e.X; ; names++ {
+		inner, ok := root.(*ast.SelectorExpr)
+		if !ok {
+			return root, names
+		}
+		root = inner.X
+	}
+}
+
+// abbreviatedSelector renders the selector chain e as "a...e" when it has
+// more names than -max-selector allows. It reports false for chains short
+// enough to be rendered in full.
+func (a *analyzer) abbreviatedSelector(e *ast.SelectorExpr) (string, bool) {
+	if a == nil || a.opts.MaxSelector <= 0 {
+		return "", false
+	}
+	root, names := selectorNames(e)
+	if names <= a.opts.MaxSelector {
+		return "", false
+	}
+	return a.getValue(root) + "..." + e.Sel.Name, true
+}
+
+// selectorTooltips returns DOT statements giving every node of cg with an
+// abbreviated selector chain a tooltip listing the chains in full.
+func (a *analyzer) selectorTooltips(cg *cfg.CFG) string {
+	var sb strings.Builder
+	for _, block := range cg.Blocks {
+		for i, node := range block.Nodes {
+			chains := []string{}
+			ast.Inspect(node, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if _, abbreviated := a.abbreviatedSelector(sel); !abbreviated {
+					return true
+				}
+				chains = append(chains, types.ExprString(sel))
+				return false
+			})
+			if len(chains) > 0 {
+				fmt.Fprintf(&sb, "  block_%d_node_%d [tooltip=\"%s\"];\n", block.Index, i, escapeLabel(strings.Join(chains, ", ")))
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaxSelector(t *testing.T) {
+	tests := []struct {
+		name, body string
+		max        int
+		label      string
+		tooltip    string // "" for none
+	}{
+		{"disabled", "x := a.b.c.d.e", 0, "x = a.b.c.d.e", ""},
+		{"five names", "x := a.b.c.d.e", 3, "x = a...e", "a.b.c.d.e"},
+		{"at the limit", "x := a.b.c.d.e", 5, "x = a.b.c.d.e", ""},
+		{"above the limit", "x := a.b.c.d.e", 4, "x = a...e", "a.b.c.d.e"},
+		{"call root", "f().b.c.d.e()", 3, "f()...e()", "f().b.c.d.e"},
+		{"two chains", "x := a.b.c.d.e + p.q.r.s", 3, "x = a...e + p...s", "a.b.c.d.e, p.q.r.s"},
+		{"condition", "if a.b.c.d.e > 0 {\n\ty()\n}", 3, "a...e > 0", "a.b.c.d.e"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.MaxSelector = test.max
+			dot := snippetDot(t, opts, test.body)
+			if !hasLabel(dot, test.label) {
+				t.Errorf("no node labeled %q:\n%s", test.label, dot)
+			}
+			if hasTooltip := strings.Contains(dot, "tooltip="); hasTooltip != (test.tooltip != "") {
+				t.Errorf("tooltip present = %v, want %q:\n%s", hasTooltip, test.tooltip, dot)
+			} else if test.tooltip != "" && !strings.Contains(dot, `[tooltip="`+test.tooltip+`"];`) {
+				t.Errorf("no tooltip %q:\n%s", test.tooltip, dot)
+			}
+		})
+	}
+}
+
+func TestDuplicateConditionsIgnoreAbbreviation(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MaxSelector = 2
+	// Both conditions are labeled "a...d > 0" but differ in full.
+	body := "if a.b.c.d > 0 {\n} else if a.x.y.d > 0 {\n}"
+	if dot := snippetDot(t, opts, body); !hasLabel(dot, "a...d > 0") {
+		t.Errorf("no node labeled \"a...d > 0\":\n%s", dot)
+	}
+	fset, _, fn := parseSnippet(t, body)
+	if findings := duplicateConditions(fset, fn); len(findings) != 0 {
+		t.Errorf("distinct conditions reported as duplicates: %v", findings)
+	}
+}
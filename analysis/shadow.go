@@ -0,0 +1,172 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+// shadowDecl is a variable declaration tracked by unusedShadows.
+type shadowDecl struct {
+	ident *ast.Ident
+	used  bool
+}
+
+// unusedShadows reports variables declared with := or var in fn that are
+// shadowed by a declaration of the same name in an inner scope before the
+// outer variable is ever used, as in
+//
+//	err := f()
+//	if ok {
+//		err := g() // the outer err is never checked
+//	}
+//
+// Scopes are tracked syntactically: blocks, the headers of if, for, range,
+// switch and select statements, case clauses and function literals.
+// Assigning to a variable is not a use of it.
+func unusedShadows(fset *token.FileSet, fn *ast.FuncDecl) []Finding {
+	var findings []Finding
+	var scopes []map[string]*shadowDecl
+	lookup := func(name string) *shadowDecl {
+		for i := len(scopes) - 1; i >= 0; i-- {
+			if decl, ok := scopes[i][name]; ok {
+				return decl
+			}
+		}
+		return nil
+	}
+	declare := func(ident *ast.Ident, used bool) {
+		top := scopes[len(scopes)-1]
+		if ident.Name == "_" || top[ident.Name] != nil {
+			return
+		}
+		if outer := lookup(ident.Name); outer != nil && !outer.used {
+			findings = append(findings, newFinding(fset, ident, "unused-shadowed", "warning",
+				fmt.Sprintf("%s shadows the declaration at line %d, which is never used before", ident.Name, fset.Position(outer.ident.Pos()).Line)))
+		}
+		top[ident.Name] = &shadowDecl{ident: ident, used: used}
+	}
+	declareFields := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				declare(name, true)
+			}
+		}
+	}
+
+	var walk func(node ast.Node)
+	scoped := func(nodes ...ast.Node) {
+		scopes = append(scopes, make(map[string]*shadowDecl))
+		for _, node := range nodes {
+			walk(node)
+		}
+		scopes = scopes[:len(scopes)-1]
+	}
+	walk = func(node ast.Node) {
+		if node == nil || reflect.ValueOf(node).IsNil() {
+			return
+		}
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.Ident:
+				if decl := lookup(n.Name); decl != nil {
+					decl.used = true
+				}
+			case *ast.SelectorExpr:
+				walk(n.X)
+				return false
+			case *ast.AssignStmt:
+				for _, rhs := range n.Rhs {
+					walk(rhs)
+				}
+				for _, lhs := range n.Lhs {
+					ident, ok := lhs.(*ast.Ident)
+					switch {
+					case ok && n.Tok == token.DEFINE:
+						declare(ident, false)
+					case ok && n.Tok == token.ASSIGN:
+					default:
+						walk(lhs)
+					}
+				}
+				return false
+			case *ast.ValueSpec:
+				for _, value := range n.Values {
+					walk(value)
+				}
+				for _, name := range n.Names {
+					declare(name, false)
+				}
+				return false
+			case *ast.BlockStmt:
+				nodes := []ast.Node{}
+				for _, stmt := range n.List {
+					nodes = append(nodes, stmt)
+				}
+				scoped(nodes...)
+				return false
+			case *ast.IfStmt:
+				scoped(n.Init, n.Cond, n.Body, n.Else)
+				return false
+			case *ast.ForStmt:
+				scoped(n.Init, n.Cond, n.Post, n.Body)
+				return false
+			case *ast.RangeStmt:
+				walk(n.X)
+				scopes = append(scopes, make(map[string]*shadowDecl))
+				for _, expr := range []ast.Expr{n.Key, n.Value} {
+					if ident, ok := expr.(*ast.Ident); ok && n.Tok == token.DEFINE {
+						declare(ident, false)
+					} else if n.Tok != token.DEFINE {
+						walk(expr)
+					}
+				}
+				walk(n.Body)
+				scopes = scopes[:len(scopes)-1]
+				return false
+			case *ast.SwitchStmt:
+				scoped(n.Init, n.Tag, n.Body)
+				return false
+			case *ast.TypeSwitchStmt:
+				scoped(n.Init, n.Assign, n.Body)
+				return false
+			case *ast.CaseClause:
+				nodes := []ast.Node{}
+				for _, expr := range n.List {
+					nodes = append(nodes, expr)
+				}
+				for _, stmt := range n.Body {
+					nodes = append(nodes, stmt)
+				}
+				scoped(nodes...)
+				return false
+			case *ast.CommClause:
+				nodes := []ast.Node{n.Comm}
+				for _, stmt := range n.Body {
+					nodes = append(nodes, stmt)
+				}
+				scoped(nodes...)
+				return false
+			case *ast.FuncLit:
+				scopes = append(scopes, make(map[string]*shadowDecl))
+				declareFields(n.Type.Params)
+				declareFields(n.Type.Results)
+				walk(n.Body)
+				scopes = scopes[:len(scopes)-1]
+				return false
+			}
+			return true
+		})
+	}
+
+	scopes = append(scopes, make(map[string]*shadowDecl))
+	declareFields(fn.Recv)
+	declareFields(fn.Type.Params)
+	declareFields(fn.Type.Results)
+	walk(fn.Body)
+	return findings
+}
@@ -0,0 +1,194 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+var (
+	dotLineIDs = regexp.MustCompile(`block_(\d+)(?:_node_\d+)?`)
+	dotNodeID  = regexp.MustCompile(`^block_\d+(?:_node_\d+)?$`)
+	dotLabel   = regexp.MustCompile(`label="((?:[^"\\]|\\.)*)"`)
+	unsafeName = regexp.MustCompile(`[^\p{L}\p{N}_]+`)
+)
+
+// splitName returns the name of fn's split graphs, which is both a file name
+// and a DOT node ID: funcName with punctuation folded into underscores, so
+// the methods (*A).String and (*B).String become A_String and B_String.
+func splitName(fn *ast.FuncDecl) string {
+	return strings.Trim(unsafeName.ReplaceAllString(funcName(fn), "_"), "_")
+}
+
+// splitScopes returns the statements of fn's body that get a sub-graph of
+// their own with Options.Split: every top-level loop, if, switch and select.
+func splitScopes(fn *ast.FuncDecl) []ast.Stmt {
+	var scopes []ast.Stmt
+	for _, stmt := range fn.Body.List {
+		if labeled, ok := stmt.(*ast.LabeledStmt); ok {
+			stmt = labeled.Stmt
+		}
+		switch stmt.(type) {
+		case *ast.ForStmt, *ast.RangeStmt, *ast.IfStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			scopes = append(scopes, stmt)
+		}
+	}
+	return scopes
+}
+
+// blockScopes maps every block index of cg to the index of the scope
+// containing it, or -1 for blocks at the top level of the function. A block
+// belongs to a scope when its first node lies inside the scope statement; a
+// block without nodes is placed by the statement that gave rise to it.
+func blockScopes(cg *cfg.CFG, scopes []ast.Stmt) map[int32]int {
+	result := make(map[int32]int)
+	for _, block := range cg.Blocks {
+		result[block.Index] = -1
+		var node ast.Node
+		if len(block.Nodes) > 0 {
+			node = block.Nodes[0]
+		} else if block.Stmt != nil && !isDoneKind(block.Kind) {
+			node = block.Stmt
+		}
+		if node == nil {
+			continue
+		}
+		for i, scope := range scopes {
+			if scope.Pos() <= node.Pos() && node.Pos() < scope.End() {
+				result[block.Index] = i
+				break
+			}
+		}
+	}
+	return result
+}
+
+// isDoneKind reports whether blocks of kind follow the statement that gave
+// rise to them rather than being part of it.
+func isDoneKind(kind cfg.BlockKind) bool {
+	switch kind {
+	case cfg.KindIfDone, cfg.KindForDone, cfg.KindRangeDone, cfg.KindSwitchDone,
+		cfg.KindSelectDone:
+		return true
+	}
+	return false
+}
+
+// splitDot splits the DOT graph of fn into a top-level graph and one
+// sub-graph per scope returned by splitScopes. Nodes of a scope are replaced
+// in the top-level graph by a single placeholder node linking to the scope's
+// file; in a sub-graph, nodes outside the scope are drawn as plain-text
+// references. The result maps file base names (without extension) to DOT
+// sources.
+func (a *analyzer) splitDot(fn *ast.FuncDecl, cg *cfg.CFG, dot string) map[string]string {
+	scopes := splitScopes(fn)
+	owner := blockScopes(cg, scopes)
+	mainName := splitName(fn)
+	scopeName := func(i int) string { return fmt.Sprintf("%s_scope_%d", mainName, i+1) }
+	scopeOf := func(id string) int {
+		m := dotLineIDs.FindStringSubmatch(id)
+		if m == nil {
+			return -1
+		}
+		index, _ := strconv.Atoi(m[1])
+		return owner[int32(index)]
+	}
+
+	labels := make(map[string]string)
+	bodies := make([][]string, len(scopes)+1) // index 0 is the top-level graph
+	external := make([]map[string]bool, len(scopes))
+	for i := range external {
+		external[i] = make(map[string]bool)
+	}
+	for _, line := range strings.Split(dot, "\n") {
+		head, attrs, _ := strings.Cut(strings.TrimSpace(line), " [")
+		if attrs != "" {
+			attrs = " [" + attrs
+		}
+		from, to, isEdge := strings.Cut(head, " -> ")
+		if !dotNodeID.MatchString(from) || (isEdge && !dotNodeID.MatchString(to)) {
+			continue // graph header and footer
+		}
+		if !isEdge {
+			if m := dotLabel.FindStringSubmatch(attrs); m != nil {
+				labels[from] = m[1]
+			}
+			s := scopeOf(from)
+			bodies[s+1] = append(bodies[s+1], "  "+head+attrs)
+			continue
+		}
+		fromScope, toScope := scopeOf(from), scopeOf(to)
+		if fromScope == toScope {
+			bodies[fromScope+1] = append(bodies[fromScope+1], "  "+head+attrs)
+			continue
+		}
+		// Crossing edge: the top-level graph connects the placeholders, each
+		// scope graph keeps its own end and references the other.
+		mainFrom, mainTo := from, to
+		if fromScope >= 0 {
+			mainFrom = scopeName(fromScope)
+			bodies[fromScope+1] = append(bodies[fromScope+1], fmt.Sprintf("  %s -> %s%s", from, to, attrs))
+			external[fromScope][to] = true
+		}
+		if toScope >= 0 {
+			mainTo = scopeName(toScope)
+			bodies[toScope+1] = append(bodies[toScope+1], fmt.Sprintf("  %s -> %s%s", from, to, attrs))
+			external[toScope][from] = true
+		}
+		if edge := fmt.Sprintf("  %s -> %s%s", mainFrom, mainTo, attrs); mainFrom != mainTo && !slices.Contains(bodies[0], edge) {
+			bodies[0] = append(bodies[0], edge)
+		}
+	}
+
+	graphs := make(map[string]string)
+	top := "digraph G {\n"
+	for i, scope := range scopes {
+		top += fmt.Sprintf("  %s [shape=box3d label=\"%s\\n(see %s)\" URL=\"%s.svg\"];\n",
+			scopeName(i), escapeLabel(a.scopeLabel(scope)), scopeName(i), scopeName(i))
+	}
+	graphs[mainName] = top + strings.Join(bodies[0], "\n") + "\n}\n"
+	for i := range scopes {
+		sub := "digraph G {\n"
+		ids := make([]string, 0, len(external[i]))
+		for id := range external[i] {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			target := mainName
+			if s := scopeOf(id); s >= 0 {
+				target = scopeName(s)
+			}
+			sub += fmt.Sprintf("  %s [shape=plaintext label=\"%s\\n(see %s)\" URL=\"%s.svg\"];\n", id, labels[id], target, target)
+		}
+		graphs[scopeName(i)] = sub + strings.Join(bodies[i+1], "\n") + "\n}\n"
+	}
+	return graphs
+}
+
+// scopeLabel renders the header of a scope statement for its placeholder.
+func (a *analyzer) scopeLabel(stmt ast.Stmt) string {
+	switch s := stmt.(type) {
+	case *ast.ForStmt:
+		return a.forLabel(s)
+	case *ast.RangeStmt:
+		return "for range " + a.getValue(s.X)
+	case *ast.IfStmt:
+		return "if " + a.getValue(s.Cond)
+	case *ast.SwitchStmt:
+		if s.Tag != nil {
+			return "switch " + a.getValue(s.Tag)
+		}
+		return "switch"
+	case *ast.TypeSwitchStmt:
+		return "type switch"
+	default:
+		return "select"
+	}
+}
@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"go/ast"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitName(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"func f() {}", "f"},
+		{"func (a A) String() string { return \"\" }", "A_String"},
+		{"func (a *A) String() string { return \"\" }", "A_String"},
+		{"func (l *List[T]) Len() int { return 0 }", "List_T_Len"},
+	}
+	for _, test := range tests {
+		_, _, fn := parseSource(t, "package p\n\n"+test.src+"\n")
+		if got := splitName(fn); got != test.want {
+			t.Errorf("splitName(%s) = %q, want %q", test.src, got, test.want)
+		}
+	}
+}
+
+// TestSplitSameNamedMethods checks that methods of different types sharing a
+// name get split graphs of different names.
+func TestSplitSameNamedMethods(t *testing.T) {
+	fset, file, _ := parseSource(t, `package p
+
+func (a *A) String() string {
+	if a == nil {
+		return "nil A"
+	}
+	return "A"
+}
+
+func (b *B) String() string {
+	for range 3 {
+	}
+	return "B"
+}
+`)
+	a := defaultAnalyzer()
+	graphs := make(map[string]string)
+	for _, decl := range file.Decls {
+		fn := decl.(*ast.FuncDecl)
+		cg := newCFG(fn)
+		dot, _ := a.genDot(fset, fn, cg, nil)
+		maps.Copy(graphs, a.splitDot(fn, cg, dot))
+	}
+	want := []string{"A_String", "A_String_scope_1", "B_String", "B_String_scope_1"}
+	if names := sortedKeys(graphs); !slices.Equal(names, want) {
+		t.Errorf("split into %v, want %v", names, want)
+	}
+	if graph := graphs["B_String"]; !strings.Contains(graph, `URL="B_String_scope_1.svg"`) {
+		t.Errorf("B_String does not link its scope:\n%s", graph)
+	}
+}
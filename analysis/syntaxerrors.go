@@ -0,0 +1,24 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"math"
+)
+
+// containsError reports whether one of the syntax errors errs lies within
+// node. Offsets are compared, so //line directives do not matter.
+func containsError(fset *token.FileSet, node ast.Node, errs scanner.ErrorList) bool {
+	start, end := fset.Position(node.Pos()).Offset, fset.Position(node.End()).Offset
+	if end < start {
+		// A node left unfinished by the error may have no valid end.
+		end = math.MaxInt
+	}
+	for _, err := range errs {
+		if err.Pos.Offset >= start && err.Pos.Offset <= end {
+			return true
+		}
+	}
+	return false
+}
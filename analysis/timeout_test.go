@@ -0,0 +1,39 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+)
+
+// TestTimeout checks that a done context stops the analysis between
+// functions and cuts the basis path search short, keeping what was found.
+func TestTimeout(t *testing.T) {
+	const src = "package p\n\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n"
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantFuncs int
+		wantPaths int
+	}{
+		{"not done", context.Background(), 3, 3},
+		{"done", canceled, 0, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := AnalyzeSource(test.ctx, "p.go", src, DefaultOptions())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != test.wantFuncs {
+				t.Errorf("%d functions analyzed, want %d", len(results), test.wantFuncs)
+			}
+			_, _, fn := parseSnippet(t, "if a > 0 {\n\tx()\n} else if a < 0 {\n\ty()\n}")
+			if paths := basisPaths(test.ctx, newCFG(fn)); len(paths) != test.wantPaths {
+				t.Errorf("%d basis paths, want %d", len(paths), test.wantPaths)
+			}
+		})
+	}
+}
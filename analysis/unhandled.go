@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// maxUnhandledExamples bounds the example positions kept per node type.
+const maxUnhandledExamples = 3
+
+// UnhandledNode tallies the occurrences of one AST node type that genDot or
+// getValue had no rendering for.
+type UnhandledNode struct {
+	Type     string   `json:"type"`
+	Count    int      `json:"count"`
+	Examples []string `json:"examples"`
+
+	seen map[token.Pos]bool // nodes counted so far; getValue may see one repeatedly
+}
+
+// UnhandledNodes accumulates the unhandled node types across all functions
+// analyzed with it as Options.Unhandled, keyed by type.
+type UnhandledNodes map[string]*UnhandledNode
+
+// recordUnhandled notes that node fell into an "Unhandled" branch, if the
+// options collect such nodes. The example positions are resolved in the
+// file set of the function being rendered.
+func (a *analyzer) recordUnhandled(node ast.Node) {
+	if a == nil || a.opts.Unhandled == nil {
+		return
+	}
+	typ := fmt.Sprintf("%T", node)
+	entry := a.opts.Unhandled[typ]
+	if entry == nil {
+		entry = &UnhandledNode{Type: typ, Examples: []string{}, seen: make(map[token.Pos]bool)}
+		a.opts.Unhandled[typ] = entry
+	}
+	if node.Pos().IsValid() {
+		if entry.seen[node.Pos()] {
+			return
+		}
+		entry.seen[node.Pos()] = true
+	}
+	entry.Count++
+	if len(entry.Examples) < maxUnhandledExamples && a.fset != nil && node.Pos().IsValid() {
+		entry.Examples = append(entry.Examples, a.fset.Position(node.Pos()).String())
+	}
+}
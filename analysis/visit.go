@@ -0,0 +1,33 @@
+package analysis
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// NodeVisitor is a hook for custom per-node metrics, see Options.Visitors.
+// walkCFG calls it once with a nil node when entering each live block and
+// then once for every node of that block.
+type NodeVisitor func(node ast.Node, block *cfg.Block)
+
+// walkCFG walks the live blocks of cg and calls every visitor on them. Blocks
+// are visited in index order (the order of cg.Blocks) and the nodes of a
+// block in their execution order; for each block or node all visitors are
+// called in the order given before moving on. Unreachable blocks are
+// skipped.
+func walkCFG(cg *cfg.CFG, visitors ...NodeVisitor) {
+	for _, block := range cg.Blocks {
+		if !block.Live {
+			continue
+		}
+		for _, visit := range visitors {
+			visit(nil, block)
+		}
+		for _, node := range block.Nodes {
+			for _, visit := range visitors {
+				visit(node, block)
+			}
+		}
+	}
+}
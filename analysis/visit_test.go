@@ -0,0 +1,56 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// TestVisitors checks the order in which Options.Visitors see the blocks
+// and nodes of a function, and that they are done before OnResult.
+func TestVisitors(t *testing.T) {
+	var got []string
+	record := func(name string) NodeVisitor {
+		return func(node ast.Node, block *cfg.Block) {
+			if node == nil {
+				got = append(got, fmt.Sprintf("%s: block %d", name, block.Index))
+			} else if name == "nodes" {
+				got = append(got, fmt.Sprintf("%s: %T", name, node))
+			}
+		}
+	}
+	opts := DefaultOptions()
+	opts.Visitors = []NodeVisitor{record("nodes"), record("blocks")}
+	opts.OnResult = func(result *Result) { got = append(got, "result "+result.Metrics.Name) }
+	src := SnippetSource("a := 1\nif a > 0 {\n\tx()\n}\nreturn\ny()")
+	if _, err := AnalyzeSource(context.Background(), "snippet.go", src, opts); err != nil {
+		t.Fatal(err)
+	}
+	// The block of y() is unreachable and skipped.
+	want := []string{
+		"nodes: block 0", "blocks: block 0", "nodes: *ast.AssignStmt", "nodes: *ast.BinaryExpr",
+		"nodes: block 1", "blocks: block 1", "nodes: *ast.ExprStmt",
+		"nodes: block 2", "blocks: block 2", "nodes: *ast.ReturnStmt",
+		"result _",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("visited\n%q\nwant\n%q", got, want)
+	}
+}
+
+// TestBuiltinVisitors checks that the metrics computed by visitors during
+// the analysis match those of the standalone functions.
+func TestBuiltinVisitors(t *testing.T) {
+	m := sampleMetrics(t, DefaultOptions())
+	_, _, fn := parseSource(t, SampleSource)
+	cg := newCFG(fn)
+	complexity, _, _ := cyclomatic(cg)
+	largest, average, empty := blockSizes(cg)
+	if m.Cyclomatic != complexity || m.LargestBlock != largest || m.AverageBlockSize != average || m.EmptyBlocks != empty {
+		t.Errorf("cyclomatic %d, blocks %d/%g/%d, want %d, %d/%g/%d", m.Cyclomatic, m.LargestBlock, m.AverageBlockSize, m.EmptyBlocks, complexity, largest, average, empty)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// funcAST is one entry of the -format ast-json output.
+type funcAST struct {
+	Function string            `json:"function"`
+	AST      *analysis.ASTNode `json:"ast"`
+}
+
+// writeASTJSON writes the syntax trees of results as a JSON array.
+func writeASTJSON(w io.Writer, results []*analysis.Result) error {
+	items := []funcAST{}
+	for _, result := range results {
+		items = append(items, funcAST{Function: result.Metrics.Name, AST: result.AST})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+func TestWriteASTJSON(t *testing.T) {
+	opts := analysis.DefaultOptions()
+	opts.AST = true
+	results, err := analysis.AnalyzeSource(context.Background(), "p.go", "package p\n\nfunc f() {\n\tx := 1\n}\n", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := writeASTJSON(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	var items []struct {
+		Function string
+		AST      struct {
+			Type, Pos, End string
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &items); err != nil {
+		t.Fatalf("%v:\n%s", err, out.String())
+	}
+	if len(items) != 1 || items[0].Function != "f" || items[0].AST.Type != "FuncDecl" {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	if ast := items[0].AST; ast.Pos != "p.go:3:1" || ast.End != "p.go:5:2" {
+		t.Errorf("range %s-%s, want p.go:3:1-p.go:5:2", ast.Pos, ast.End)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// baselineMetrics lists the metrics compared against a baseline; a function
+// regresses when any of them grows by more than -baseline-delta.
+var baselineMetrics = []struct {
+	name  string
+	value func(analysis.Metrics) float64
+}{
+	{"cyclomatic", func(m analysis.Metrics) float64 { return float64(m.Cyclomatic) }},
+	{"cognitive", func(m analysis.Metrics) float64 { return float64(m.Cognitive) }},
+	{"chepin", func(m analysis.Metrics) float64 { return m.Chepin }},
+}
+
+// baselineKey identifies a function across runs: its file relative to the
+// working directory and its name.
+func baselineKey(m analysis.Metrics) string {
+	file := m.File
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, file); err == nil && filepath.IsAbs(file) {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file) + ":" + m.Name
+}
+
+// writeBaseline stores the metrics of results in path using the
+// -format metrics-json serialization.
+func writeBaseline(path string, results []*analysis.Result) error {
+	var buf bytes.Buffer
+	if err := writeMetricsJSON(&buf, results); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// readBaseline loads the per-function metrics stored by writeBaseline.
+func readBaseline(path string) (map[string]analysis.Metrics, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []analysis.Metrics
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	baseline := make(map[string]analysis.Metrics)
+	for _, m := range items {
+		if m.Kind == "function" {
+			baseline[baselineKey(m)] = m
+		}
+	}
+	return baseline, nil
+}
+
+// checkBaseline reports to w every function that is new or whose metrics got
+// worse than in the baseline by more than delta, and returns how many were
+// reported.
+func checkBaseline(w io.Writer, baseline map[string]analysis.Metrics, results []*analysis.Result, delta float64) int {
+	reported := 0
+	for _, result := range results {
+		current := result.Metrics
+		key := baselineKey(current)
+		old, ok := baseline[key]
+		if !ok {
+			fmt.Fprintf(w, "New function: %s\n", key)
+			reported++
+			continue
+		}
+		for _, metric := range baselineMetrics {
+			if before, after := metric.value(old), metric.value(current); after-before > delta {
+				fmt.Fprintf(w, "Regressed: %s %s %g -> %g\n", key, metric.name, before, after)
+				reported++
+			}
+		}
+	}
+	return reported
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// -stdin-batch protocol
+//
+// The input on stdin is a JSON array of files:
+//
+//	[{"filename": "a.go", "content": "package a\n..."}, ...]
+//
+// Every file is analyzed on its own and the output on stdout is a JSON
+// array with one report per input file, in input order:
+//
+//	[{"filename": "a.go", "functions": [<metrics>...], "aggregate": {...},
+//	  "findings": [<finding>...]}, ...]
+//
+// The metrics and finding objects are those of -format metrics-json and
+// -format findings-json; -metrics leaves out the same metric fields. A file
+// that does not parse gets an "error" field instead of functions and does
+// not stop the batch.
+
+// batchFile is one input file of -stdin-batch.
+type batchFile struct {
+	Filename string `json:"filename"`
+	Content  string `json:"content"`
+}
+
+// batchReport is the report written for one batchFile.
+type batchReport struct {
+	Filename  string             `json:"filename"`
+	Functions []any              `json:"functions"` // see selectedFields
+	Aggregate *aggregateMetrics  `json:"aggregate,omitempty"`
+	Findings  []analysis.Finding `json:"findings"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// runBatch reads the files of a -stdin-batch request from r, analyzes them
+// until ctx is done and writes the reports to w. It returns the results of
+// all files.
+func runBatch(ctx context.Context, r io.Reader, w io.Writer) ([]*analysis.Result, error) {
+	var files []batchFile
+	if err := json.NewDecoder(r).Decode(&files); err != nil {
+		return nil, fmt.Errorf("decoding batch: %w", err)
+	}
+	var all []*analysis.Result
+	reports := []batchReport{}
+	for _, file := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		report := batchReport{Filename: file.Filename, Functions: []any{}, Findings: []analysis.Finding{}}
+		results, err := analyzeSource(ctx, file.Filename, file.Content)
+		if err != nil {
+			report.Error = err.Error()
+		} else {
+			for _, result := range results {
+				item, err := selectedFields(result.Metrics)
+				if err != nil {
+					return nil, err
+				}
+				report.Functions = append(report.Functions, item)
+				report.Findings = append(report.Findings, result.Findings...)
+			}
+			agg := aggregate(results)
+			report.Aggregate = &agg
+			all = append(all, results...)
+		}
+		reports = append(reports, report)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return all, enc.Encode(reports)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunBatch(t *testing.T) {
+	defer func(format string) { *outputFormat = format }(*outputFormat)
+	defer func(selected map[string]bool) { selectedMetrics = selected }(selectedMetrics)
+	*outputFormat = "metrics-json"
+	type fileReport struct {
+		filename  string
+		functions []string // names
+		err       bool
+	}
+	tests := []struct {
+		name, input string
+		metrics     string // -metrics, "" for all
+		want        []fileReport
+		wantFields  []string // the fields of every function, nil to skip
+		wantErr     bool
+	}{
+		{"empty", `[]`, "", []fileReport{}, nil, false},
+		{"files in order", `[
+			{"filename": "b.go", "content": "package b\nfunc B() {}\nfunc C() {}\n"},
+			{"filename": "a.go", "content": "package a\nfunc A() {}\n"}
+		]`, "", []fileReport{
+			{"b.go", []string{"B", "C"}, false},
+			{"a.go", []string{"A"}, false},
+		}, nil, false},
+		{"unparsable file", `[
+			{"filename": "bad.go", "content": "not go"},
+			{"filename": "a.go", "content": "package a\nfunc A() {}\n"}
+		]`, "", []fileReport{
+			{"bad.go", nil, true},
+			{"a.go", []string{"A"}, false},
+		}, nil, false},
+		{"metric selection", `[
+			{"filename": "a.go", "content": "package a\nfunc A() {}\n"}
+		]`, "cyclomatic,recursion", []fileReport{
+			{"a.go", []string{"A"}, false},
+		}, []string{"ast_cyclomatic", "cyclomatic", "file", "kind", "line", "lines", "name", "recursive_calls"}, false},
+		{"not an array", `{"filename": "a.go"}`, "", nil, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selectedMetrics = nil
+			if test.metrics != "" {
+				var err error
+				if selectedMetrics, err = parseMetricSelection(test.metrics); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var out bytes.Buffer
+			_, err := runBatch(context.Background(), strings.NewReader(test.input), &out)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("runBatch error %v, want error %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			var reports []struct {
+				Filename  string           `json:"filename"`
+				Functions []map[string]any `json:"functions"`
+				Error     string           `json:"error"`
+			}
+			if err := json.Unmarshal(out.Bytes(), &reports); err != nil {
+				t.Fatalf("output is not a JSON array of reports: %v\n%s", err, out.String())
+			}
+			if len(reports) != len(test.want) {
+				t.Fatalf("%d reports, want %d:\n%s", len(reports), len(test.want), out.String())
+			}
+			for i, report := range reports {
+				want := test.want[i]
+				if report.Filename != want.filename || (report.Error != "") != want.err {
+					t.Errorf("report %d is for %q with error %q, want %q with error %v", i, report.Filename, report.Error, want.filename, want.err)
+				}
+				var names []string
+				for _, function := range report.Functions {
+					names = append(names, function["name"].(string))
+					if test.wantFields != nil {
+						if fields := sortedKeys(function); !slices.Equal(fields, test.wantFields) {
+							t.Errorf("function fields %v, want %v", fields, test.wantFields)
+						}
+					}
+				}
+				if !slices.Equal(names, want.functions) {
+					t.Errorf("report %d has functions %v, want %v", i, names, want.functions)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// writeCallGraph writes the call graph of results to path, or to stdout if
+// path is "-".
+func writeCallGraph(path string, results []*analysis.Result) error {
+	dot := analysis.CallGraphDot(results)
+	if path == "-" {
+		_, err := fmt.Print(dot)
+		return err
+	}
+	return os.WriteFile(path, []byte(dot), 0o644)
+}
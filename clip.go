@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// clipboardCommands lists the clipboard utilities tried by -clip, in order.
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+	{"clip"},
+}
+
+// copyToClipboard copies text to the system clipboard with the first
+// clipboard utility found on the PATH.
+func copyToClipboard(text string) error {
+	tried := []string{}
+	for _, command := range clipboardCommands {
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			tried = append(tried, command[0])
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %v: %s", command[0], err, out)
+		}
+		return nil
+	}
+	return fmt.Errorf("no clipboard utility found (tried %s)", strings.Join(tried, ", "))
+}
+
+// clipResults copies the DOT graphs of results to the clipboard for pasting
+// into an online Graphviz renderer, which only draws the first graph of its
+// input; see analysis.ClusterDot.
+func clipResults(results []*analysis.Result) {
+	if err := copyToClipboard(analysis.ClusterDot(results)); err != nil {
+		fmt.Fprintf(summaryOutput(), "Cannot copy to the clipboard: %v\n", err)
+		return
+	}
+	fmt.Fprintf(summaryOutput(), "Copied the DOT of %d functions to the clipboard.\n", len(results))
+}
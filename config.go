@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configName is the name of the configuration file looked up, when -config
+// is not given, in the directory of the -package pattern (or the working
+// directory) and then in each of its parents.
+//
+// The file is a JSON object whose keys are flag names without the dash and
+// whose values are the flag values: strings, numbers, booleans, or arrays of
+// strings for the comma-separated flags, as in
+//
+//	{
+//		"max-complexity": 15,
+//		"max-statements": 60,
+//		"exclude": ["*_gen.go", "testdata/*"],
+//		"color-scheme": "colorblind-safe",
+//		"metrics": ["cyclomatic", "cognitive", "chepin"]
+//	}
+//
+// The file only sets defaults: a flag given on the command line wins.
+const configName = ".cfganalysis.json"
+
+// findConfig returns the path of the nearest configName in dir or one of
+// its parents, or "" if there is none.
+func findConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, configName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// configDir returns the directory the configuration lookup starts from: the
+// directory named by the -package pattern, if it is one, as in ./cmd/...,
+// and the working directory otherwise.
+func configDir() string {
+	dir := strings.TrimSuffix(*packagePattern, "/...")
+	if info, err := os.Stat(dir); dir != "" && err == nil && info.IsDir() {
+		return dir
+	}
+	return "."
+}
+
+// applyConfig sets the flags listed in the configuration file at path that
+// were not given on the command line.
+func applyConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := flag.Lookup(name)
+		if f == nil || name == "config" {
+			return fmt.Errorf("%s: unknown option %q", path, name)
+		}
+		if given[name] {
+			continue
+		}
+		value, err := configValue(settings[name])
+		if err == nil {
+			err = f.Value.Set(value)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: option %q: %v", path, name, err)
+		}
+	}
+	return nil
+}
+
+// configValue converts a JSON value of the configuration file to the
+// command-line form of a flag value.
+func configValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("list item %v is not a string", item)
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", v)
+}
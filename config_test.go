@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfigValue(t *testing.T) {
+	tests := []struct {
+		value   any
+		want    string
+		wantErr bool
+	}{
+		{"colorblind-safe", "colorblind-safe", false},
+		{true, "true", false},
+		{float64(15), "15", false},
+		{1.5, "1.5", false},
+		{[]any{"*_gen.go", "testdata/*"}, "*_gen.go,testdata/*", false},
+		{[]any{"a", 1.0}, "", true},
+		{map[string]any{}, "", true},
+		{nil, "", true},
+	}
+	for _, test := range tests {
+		got, err := configValue(test.value)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("configValue(%v) = %q, %v, want %q, error %v", test.value, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := findConfig(nested); got != "" {
+		t.Skipf("%s above the test directory is in the way", got)
+	}
+	config := filepath.Join(root, "a", configName)
+	if err := os.WriteFile(config, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{nested, filepath.Join(root, "a")} {
+		if got := findConfig(dir); got != config {
+			t.Errorf("findConfig(%s) = %q, want %q", dir, got, config)
+		}
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	names := []string{"max-statements", "exclude", "color-scheme", "max-complexity"}
+	saved := make(map[string]string)
+	for _, name := range names {
+		saved[name] = flag.Lookup(name).Value.String()
+	}
+	defer func() {
+		// Value.Set, unlike flag.Set, does not mark the flags as given.
+		for name, value := range saved {
+			flag.Lookup(name).Value.Set(value)
+		}
+	}()
+	// A flag given on the command line is not overridden.
+	if err := flag.Set("max-complexity", "7"); err != nil {
+		t.Fatal(err)
+	}
+	defer func(format string) { *outputFormat = format }(*outputFormat)
+	*outputFormat = "metrics-json"
+	// f has three statements.
+	const src = "package p\n\nfunc f() {\n\tx()\n\ty()\n\tz()\n}\n"
+
+	tests := []struct {
+		name, config string
+		want         map[string]string
+		wantRules    []string // the rules of the findings for src
+		wantErr      string
+	}{
+		{"values", `{"max-statements": 60, "exclude": ["*_gen.go", "vendor/"], "color-scheme": "grayscale", "max-complexity": 15}`,
+			map[string]string{"max-statements": "60", "exclude": "*_gen.go,vendor/", "color-scheme": "grayscale", "max-complexity": "7"}, nil, ""},
+		{"threshold", `{"max-statements": 2}`, map[string]string{"max-statements": "2"}, []string{"too-many-statements"}, ""},
+		{"unknown option", `{"max-complexty": 15}`, nil, nil, `unknown option "max-complexty"`},
+		{"config option", `{"config": "other.json"}`, nil, nil, `unknown option "config"`},
+		{"bad value", `{"max-statements": "many"}`, nil, nil, `option "max-statements"`},
+		{"not an object", `[1, 2]`, nil, nil, "cannot unmarshal"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), configName)
+			if err := os.WriteFile(path, []byte(test.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := applyConfig(path)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("error %v, want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range test.want {
+				if got := flag.Lookup(name).Value.String(); got != want {
+					t.Errorf("-%s = %q, want %q", name, got, want)
+				}
+			}
+			results, err := analyzeSource(context.Background(), "p.go", src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rules []string
+			for _, finding := range results[0].Findings {
+				rules = append(rules, finding.RuleID)
+			}
+			if !slices.Equal(rules, test.wantRules) {
+				t.Errorf("findings for rules %v, want %v", rules, test.wantRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// sqliteDriver is the database/sql driver name registered by the pure-Go
+// driver modernc.org/sqlite.
+const sqliteDriver = "sqlite"
+
+// dbSchema creates the table -db appends to: one row per analyzed function
+// and run.
+const dbSchema = `CREATE TABLE IF NOT EXISTS metrics (
+	run_at             TEXT NOT NULL,
+	git_commit         TEXT NOT NULL,
+	file               TEXT NOT NULL,
+	function           TEXT NOT NULL,
+	line               INTEGER NOT NULL,
+	lines              INTEGER NOT NULL,
+	statements         INTEGER NOT NULL,
+	control_flow_ratio REAL NOT NULL,
+	cyclomatic         INTEGER NOT NULL,
+	ast_cyclomatic     INTEGER NOT NULL,
+	cognitive          INTEGER NOT NULL,
+	chepin             REAL NOT NULL,
+	chepin_p           INTEGER NOT NULL,
+	chepin_m           INTEGER NOT NULL,
+	chepin_c           INTEGER NOT NULL,
+	chepin_t           INTEGER NOT NULL,
+	max_expr_depth     INTEGER NOT NULL,
+	basis_paths        INTEGER NOT NULL,
+	variables          INTEGER NOT NULL,
+	recursive_calls    INTEGER NOT NULL
+)`
+
+// writeDB appends the metrics of results to the SQLite database at path,
+// creating the table if needed. Every row of a run shares its timestamp and
+// the commit checked out in the working directory, if any.
+func writeDB(path string, results []*analysis.Result) error {
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err := db.Exec(dbSchema); err != nil {
+		return fmt.Errorf("creating schema: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT INTO metrics VALUES (` + strings.Repeat("?, ", 19) + `?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	runAt := time.Now().UTC().Format(time.RFC3339)
+	commit := currentCommit()
+	for _, result := range results {
+		m := result.Metrics
+		if _, err := stmt.Exec(runAt, commit, m.File, m.Name, m.Line, m.Lines, m.Statements, m.ControlFlowRatio,
+			m.Cyclomatic, m.ASTCyclomatic, m.Cognitive, m.Chepin, m.ChepinP, m.ChepinM, m.ChepinC, m.ChepinT,
+			m.MaxExprDepth, m.BasisPaths, m.Vocabulary, m.RecursiveCalls); err != nil {
+			return fmt.Errorf("inserting %s: %w", m.Name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// currentCommit returns the hash of the git commit checked out in the
+// working directory, or "" outside a repository.
+func currentCommit() string {
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+func TestWriteDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.db")
+	results := []*analysis.Result{
+		{Metrics: analysis.Metrics{Name: "f", File: "a.go", Line: 3, Cyclomatic: 4, Chepin: 2.5}},
+		{Metrics: analysis.Metrics{Name: "T.g", File: "a.go", Line: 9, Cyclomatic: 1}},
+	}
+	// Two runs append to the same table.
+	for range 2 {
+		if err := writeDB(path, results); err != nil {
+			t.Fatalf("writeDB: %v", err)
+		}
+	}
+
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query(`SELECT function, line, cyclomatic, chepin FROM metrics ORDER BY function, rowid`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []analysis.Metrics
+	for rows.Next() {
+		var m analysis.Metrics
+		if err := rows.Scan(&m.Name, &m.Line, &m.Cyclomatic, &m.Chepin); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, m)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []analysis.Metrics{
+		{Name: "T.g", Line: 9, Cyclomatic: 1},
+		{Name: "T.g", Line: 9, Cyclomatic: 1},
+		{Name: "f", Line: 3, Cyclomatic: 4, Chepin: 2.5},
+		{Name: "f", Line: 3, Cyclomatic: 4, Chepin: 2.5},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("read back %d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Name != w.Name || g.Line != w.Line || g.Cyclomatic != w.Cyclomatic || g.Chepin != w.Chepin {
+			t.Errorf("row %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lineRange is an inclusive range of line numbers.
+type lineRange struct {
+	start, end int
+}
+
+// changedLines maps the files touched by the -diff input, as written in the
+// diff, to their changed line ranges. It is nil when no diff restricts the
+// analysis.
+var changedLines map[string][]lineRange
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// loadDiff reads the unified diff named by source: "-" reads standard input,
+// "git" runs git diff against HEAD and "git:<rev>" against the given revision;
+// anything else is a path to a diff file.
+func loadDiff(source string) (map[string][]lineRange, error) {
+	var r io.Reader
+	switch {
+	case source == "-":
+		r = os.Stdin
+	case source == "git" || strings.HasPrefix(source, "git:"):
+		rev := "HEAD"
+		if _, after, ok := strings.Cut(source, ":"); ok {
+			rev = after
+		}
+		out, err := exec.Command("git", "diff", "-U0", rev).Output()
+		if err != nil {
+			return nil, fmt.Errorf("git diff %s: %v", rev, err)
+		}
+		r = strings.NewReader(string(out))
+	default:
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return parseDiff(r)
+}
+
+// parseDiff extracts the changed line ranges of the new side of a unified
+// diff. Only added lines count as changed, not the context around them; a
+// deletion marks the new-side line it followed, or line 1 at the top of the
+// file.
+func parseDiff(r io.Reader) (map[string][]lineRange, error) {
+	ranges := make(map[string][]lineRange)
+	var file string
+	// line is the next new-side line number; oldLeft and newLeft count the
+	// lines of the current hunk body still to be read, and deleted is set
+	// while deletions wait to be marked.
+	var line, oldLeft, newLeft int
+	var deleted bool
+	mark := func(n int) {
+		n = max(n, 1)
+		rs := ranges[file]
+		if last := len(rs) - 1; last >= 0 && rs[last].start <= n && n <= rs[last].end+1 {
+			rs[last].end = max(rs[last].end, n)
+			return
+		}
+		ranges[file] = append(rs, lineRange{n, n})
+	}
+	flush := func() {
+		if deleted {
+			mark(line - 1)
+			deleted = false
+		}
+	}
+	count := func(s string) int {
+		if s == "" {
+			return 1
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				// Lines replaced by additions need no deletion mark.
+				deleted = false
+				mark(line)
+				line++
+				newLeft--
+			case strings.HasPrefix(text, "-"):
+				deleted = true
+				oldLeft--
+			case strings.HasPrefix(text, "\\"): // \ No newline at end of file
+			default:
+				flush()
+				line++
+				oldLeft--
+				newLeft--
+			}
+			if oldLeft <= 0 && newLeft <= 0 {
+				flush()
+			}
+			continue
+		}
+		if name, ok := strings.CutPrefix(text, "+++ "); ok {
+			name, _, _ = strings.Cut(name, "\t")
+			file = ""
+			if name != "/dev/null" {
+				file = strings.TrimPrefix(name, "b/")
+			}
+			continue
+		}
+		m := hunkHeader.FindStringSubmatch(text)
+		if m == nil || file == "" {
+			continue
+		}
+		oldLeft, newLeft = count(m[1]), count(m[3])
+		line, _ = strconv.Atoi(m[2])
+		if newLeft == 0 {
+			// The new-side start of a pure deletion is the line before it.
+			line++
+		}
+	}
+	return ranges, scanner.Err()
+}
+
+// inDiffScope reports whether fn overlaps a line changed by the -diff input.
+// Every function is in scope when no diff was given.
+func inDiffScope(fset *token.FileSet, fn *ast.FuncDecl) bool {
+	if changedLines == nil {
+		return true
+	}
+	start := fset.Position(fn.Pos())
+	end := fset.Position(fn.End())
+	for file, ranges := range changedLines {
+		if !sameFile(start.Filename, file) {
+			continue
+		}
+		for _, r := range ranges {
+			if r.start <= end.Line && start.Line <= r.end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameFile reports whether the parsed file name refers to the diff path,
+// which is relative to the repository root.
+func sameFile(filename, diffPath string) bool {
+	filename = filepath.ToSlash(filename)
+	return filename == diffPath || strings.HasSuffix(filename, "/"+diffPath)
+}
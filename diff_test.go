@@ -0,0 +1,178 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	tests := []struct {
+		name, diff string
+		want       map[string][]lineRange
+	}{
+		{"added line with context", `diff --git a/p.go b/p.go
+--- a/p.go
++++ b/p.go
+@@ -3,6 +3,7 @@ func a() {
+ 	x := 1
+ 	y := 2
+ 	z := 3
++	w := 4
+ }
+ 
+ func b() {
+`, map[string][]lineRange{"p.go": {{6, 6}}}},
+		{"replaced lines", `--- a/p.go
++++ b/p.go
+@@ -4,2 +4,3 @@
+ 	a()
+-	b()
++	c()
++	d()
+`, map[string][]lineRange{"p.go": {{5, 6}}}},
+		{"pure deletion", `--- a/p.go
++++ b/p.go
+@@ -10,3 +10,2 @@
+ 	a()
+-	b()
+ 	c()
+`, map[string][]lineRange{"p.go": {{10, 10}}}},
+		{"pure deletion without context", `--- a/p.go
++++ b/p.go
+@@ -1 +0,0 @@
+-// Package p
+@@ -8,2 +6,0 @@
+-	b()
+-	c()
+`, map[string][]lineRange{"p.go": {{1, 1}, {6, 6}}}},
+		{"renamed file", `diff --git a/old.go b/new.go
+similarity index 90%
+rename from old.go
+rename to new.go
+--- a/old.go
++++ b/new.go
+@@ -1,3 +1,3 @@
+-package old
++package new
+ 
+ func a() {}
+`, map[string][]lineRange{"new.go": {{1, 1}}}},
+		{"pure rename", `diff --git a/old.go b/new.go
+similarity index 100%
+rename from old.go
+rename to new.go
+`, map[string][]lineRange{}},
+		{"deleted file", `--- a/p.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package p
+-func a() {}
+`, map[string][]lineRange{}},
+		{"added line that looks like a header", `--- a/p.go
++++ b/p.go
+@@ -1,0 +2,1 @@
++++ b/q.go
+@@ -5 +6 @@
+-	a()
++	b()
+`, map[string][]lineRange{"p.go": {{2, 2}, {6, 6}}}},
+		{"no newline at end of file", `--- a/p.go
++++ b/p.go
+@@ -2 +2 @@
+-}
+\ No newline at end of file
++}
+`, map[string][]lineRange{"p.go": {{2, 2}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseDiff(strings.NewReader(test.diff))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseDiff = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInDiffScope(t *testing.T) {
+	defer func(lines map[string][]lineRange) { changedLines = lines }(changedLines)
+	// Lines 3-6 hold a and 8-11 hold b.
+	const src = "package p\n\nfunc a() {\n\tx := 1\n\t_ = x\n}\n\nfunc b() {\n\ty := 2\n\t_ = y\n}\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "dir/p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, diff string
+		want       string // functions in scope
+	}{
+		{"no diff", "", "a b"},
+		{"change in a with context reaching b", `--- a/p.go
++++ b/p.go
+@@ -3,7 +3,7 @@
+ func a() {
+-	x := 0
++	x := 1
+ 	_ = x
+ }
+ 
+ func b() {
+ 	y := 2
+`, "a"},
+		{"deletion in b", `--- a/p.go
++++ b/p.go
+@@ -9,3 +9,2 @@
+ 	y := 2
+-	y++
+ 	_ = y
+`, "b"},
+		{"deletion between functions", `--- a/p.go
++++ b/p.go
+@@ -7,2 +7,1 @@
+ 
+-
+`, ""},
+		{"renamed file", `diff --git a/old.go b/dir/p.go
+rename from old.go
+rename to dir/p.go
+--- a/old.go
++++ b/dir/p.go
+@@ -9 +9 @@
+-	y := 1
++	y := 2
+`, "b"},
+		{"other file", `--- a/q.go
++++ b/q.go
+@@ -4 +4 @@
+-	x := 0
++	x := 1
+`, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changedLines = nil
+			if test.diff != "" {
+				if changedLines, err = parseDiff(strings.NewReader(test.diff)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var got []string
+			for _, decl := range file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && inDiffScope(fset, fn) {
+					got = append(got, fn.Name.Name)
+				}
+			}
+			if strings.Join(got, " ") != test.want {
+				t.Errorf("in scope: %v, want %s", got, test.want)
+			}
+		})
+	}
+}
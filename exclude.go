@@ -0,0 +1,40 @@
+package main
+
+import (
+	"go/ast"
+	"path/filepath"
+	"strings"
+)
+
+// isExcluded reports whether the file at path matches one of the
+// comma-separated -exclude patterns. A pattern ending in a slash, such as
+// "vendor/", matches a directory anywhere in the path; any other pattern is
+// a glob matched against the base name and against the whole path.
+func isExcluded(path string) bool {
+	path = filepath.ToSlash(path)
+	for _, pattern := range strings.Split(*excludePatterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "":
+		case strings.HasSuffix(pattern, "/"):
+			if strings.HasPrefix(path, pattern) || strings.Contains(path, "/"+pattern) {
+				return true
+			}
+		default:
+			if ok, _ := filepath.Match(pattern, filepath.Base(path)); ok {
+				return true
+			}
+			if ok, _ := filepath.Match(pattern, path); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// skipFile reports whether file, parsed from path, is left out of the
+// analysis: it matches -exclude, or it is generated code ("// Code
+// generated ... DO NOT EDIT.") and -generated is not set.
+func skipFile(path string, file *ast.File) bool {
+	return isExcluded(path) || (!*includeGenerated && ast.IsGenerated(file))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// shortFuncs counts the functions left out by -min-lines and
+// unexportedFuncs those left out by -exported-only.
+var shortFuncs, unexportedFuncs int
+
+// targetFile and targetLine hold the -line target; targetLine is 0 without
+// one. targetMatches counts the functions found to contain it.
+var (
+	targetFile    string
+	targetLine    int
+	targetMatches int
+)
+
+// parseLineTarget parses the -line argument, a line number optionally
+// preceded by a file name, as in "42" or "main.go:42".
+func parseLineTarget(s string) (file string, line int, err error) {
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		file, s = s[:i], s[i+1:]
+	}
+	line, err = strconv.Atoi(s)
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("%q is not a line number", s)
+	}
+	return file, line, nil
+}
+
+// containsTarget reports whether fn spans the -line target. Without a file
+// name the line is looked for in every analyzed file.
+func containsTarget(fset *token.FileSet, fn *ast.FuncDecl) bool {
+	if targetLine == 0 {
+		return true
+	}
+	start := fset.Position(fn.Pos())
+	if targetFile != "" && !sameFile(start.Filename, targetFile) {
+		return false
+	}
+	if start.Line > targetLine || fset.Position(fn.End()).Line < targetLine {
+		return false
+	}
+	targetMatches++
+	return true
+}
+
+// selectFunc reports whether fn is to be analyzed: it must overlap the -diff
+// input, if any, contain the -line target, if any, span at least -min-lines
+// lines and, with -exported-only, be exported.
+func selectFunc(fset *token.FileSet, fn *ast.FuncDecl) bool {
+	if !inDiffScope(fset, fn) || !containsTarget(fset, fn) {
+		return false
+	}
+	if lines := fset.Position(fn.End()).Line - fset.Position(fn.Pos()).Line + 1; lines < *minLines {
+		shortFuncs++
+		return false
+	}
+	if *exportedOnly && !isExported(fn) {
+		unexportedFuncs++
+		return false
+	}
+	return true
+}
+
+// isExported reports whether fn is part of its package's API: its name is
+// exported and, for a method, so is its receiver type.
+func isExported(fn *ast.FuncDecl) bool {
+	if !fn.Name.IsExported() {
+		return false
+	}
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return true
+	}
+	name := recvBaseType(fn.Recv.List[0].Type)
+	return name != nil && name.IsExported()
+}
+
+// recvBaseType returns the type name of a receiver type expression such as
+// T, *T or *T[K, V], or nil if it has no name.
+func recvBaseType(expr ast.Expr) *ast.Ident {
+	switch t := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return t
+	case *ast.StarExpr:
+		return recvBaseType(t.X)
+	case *ast.IndexExpr:
+		return recvBaseType(t.X)
+	case *ast.IndexListExpr:
+		return recvBaseType(t.X)
+	}
+	return nil
+}
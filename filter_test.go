@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"testing"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// selectedFuncs returns the names of the functions of src, in the file
+// test.go, that selectFunc picks.
+func selectedFuncs(t *testing.T, src string) []string {
+	t.Helper()
+	opts := analysis.DefaultOptions()
+	opts.Select = selectFunc
+	results, err := analysis.AnalyzeSource(context.Background(), "test.go", src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Metrics.Name)
+	}
+	return names
+}
+
+const filterSrc = `package p
+
+func one() {}
+
+func three() {
+	x()
+}
+
+func Five() {
+	x()
+	y()
+	z()
+}
+
+func (t *T) Method() {
+	x()
+}
+
+func (t *t) Hidden() {
+	x()
+}
+`
+
+func TestMinLines(t *testing.T) {
+	defer func(n, skipped int) { *minLines, shortFuncs = n, skipped }(*minLines, shortFuncs)
+	tests := []struct {
+		minLines int
+		want     []string
+	}{
+		{0, []string{"one", "three", "Five", "(*T).Method", "(*t).Hidden"}},
+		{3, []string{"three", "Five", "(*T).Method", "(*t).Hidden"}},
+		{4, []string{"Five"}},
+		{6, nil},
+	}
+	for _, test := range tests {
+		*minLines, shortFuncs = test.minLines, 0
+		if got := selectedFuncs(t, filterSrc); !slices.Equal(got, test.want) {
+			t.Errorf("-min-lines %d selects %v, want %v", test.minLines, got, test.want)
+		}
+		if skipped := 5 - len(test.want); shortFuncs != skipped {
+			t.Errorf("-min-lines %d skipped %d functions, want %d", test.minLines, shortFuncs, skipped)
+		}
+	}
+}
+
+func TestExportedOnly(t *testing.T) {
+	defer func(only bool, skipped int) { *exportedOnly, unexportedFuncs = only, skipped }(*exportedOnly, unexportedFuncs)
+	tests := []struct {
+		exportedOnly bool
+		want         []string
+	}{
+		{false, []string{"one", "three", "Five", "(*T).Method", "(*t).Hidden"}},
+		{true, []string{"Five", "(*T).Method"}},
+	}
+	for _, test := range tests {
+		*exportedOnly, unexportedFuncs = test.exportedOnly, 0
+		if got := selectedFuncs(t, filterSrc); !slices.Equal(got, test.want) {
+			t.Errorf("-exported-only=%v selects %v, want %v", test.exportedOnly, got, test.want)
+		}
+		if skipped := 5 - len(test.want); unexportedFuncs != skipped {
+			t.Errorf("-exported-only=%v skipped %d functions, want %d", test.exportedOnly, unexportedFuncs, skipped)
+		}
+	}
+}
+
+func TestRecvBaseType(t *testing.T) {
+	tests := []struct {
+		recv, want string
+	}{
+		{"T", "T"},
+		{"*T", "T"},
+		{"(*T)", "T"},
+		{"T[K]", "T"},
+		{"*T[K, V]", "T"},
+		{"struct{}", ""},
+	}
+	for _, test := range tests {
+		file, err := parser.ParseFile(token.NewFileSet(), "test.go", "package p\n\nfunc (r "+test.recv+") M() {}\n", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fn := file.Decls[0].(*ast.FuncDecl)
+		got := ""
+		if ident := recvBaseType(fn.Recv.List[0].Type); ident != nil {
+			got = ident.Name
+		}
+		if got != test.want {
+			t.Errorf("recvBaseType(%s) = %q, want %q", test.recv, got, test.want)
+		}
+	}
+}
+
+func TestParseLineTarget(t *testing.T) {
+	tests := []struct {
+		arg, file string
+		line      int
+		wantErr   bool
+	}{
+		{"42", "", 42, false},
+		{"main.go:42", "main.go", 42, false},
+		{"C:/src/main.go:7", "C:/src/main.go", 7, false},
+		{"main.go", "", 0, true},
+		{"main.go:0", "", 0, true},
+		{"-3", "", 0, true},
+	}
+	for _, test := range tests {
+		file, line, err := parseLineTarget(test.arg)
+		if (err != nil) != test.wantErr || file != test.file || line != test.line {
+			t.Errorf("parseLineTarget(%q) = %q, %d, %v, want %q, %d, error %v", test.arg, file, line, err, test.file, test.line, test.wantErr)
+		}
+	}
+}
+
+func TestLineTarget(t *testing.T) {
+	defer func(file string, line, matches int) {
+		targetFile, targetLine, targetMatches = file, line, matches
+	}(targetFile, targetLine, targetMatches)
+	tests := []struct {
+		file string
+		line int
+		want []string
+	}{
+		{"", 0, []string{"one", "three", "Five", "(*T).Method", "(*t).Hidden"}},
+		{"", 3, []string{"one"}},
+		{"", 11, []string{"Five"}},
+		{"", 13, []string{"Five"}},
+		{"", 14, nil},
+		{"test.go", 16, []string{"(*T).Method"}},
+		{"dir/test.go", 16, nil},
+		{"other.go", 16, nil},
+	}
+	for _, test := range tests {
+		targetFile, targetLine, targetMatches = test.file, test.line, 0
+		if got := selectedFuncs(t, filterSrc); !slices.Equal(got, test.want) {
+			t.Errorf("-line %s:%d selects %v, want %v", test.file, test.line, got, test.want)
+		}
+		if test.line > 0 && targetMatches != len(test.want) {
+			t.Errorf("-line %s:%d matched %d functions, want %d", test.file, test.line, targetMatches, len(test.want))
+		}
+	}
+}
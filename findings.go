@@ -0,0 +1,18 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// writeFindingsJSON writes findings as a JSON array.
+func writeFindingsJSON(w io.Writer, findings []analysis.Finding) error {
+	if findings == nil {
+		findings = []analysis.Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+func TestWriteFindingsJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []analysis.Finding
+		want     string
+	}{
+		{"none", nil, "[]\n"},
+		{"one", []analysis.Finding{{
+			RuleID:   "empty-branch",
+			Message:  "empty branch",
+			Severity: "warning",
+			Pos:      token.Position{Filename: "a.go", Offset: 40, Line: 3, Column: 12},
+			End:      token.Position{Filename: "a.go", Offset: 43, Line: 4, Column: 2},
+		}}, `[
+  {
+    "rule_id": "empty-branch",
+    "message": "empty branch",
+    "severity": "warning",
+    "pos": {
+      "file": "a.go",
+      "line": 3,
+      "column": 12
+    },
+    "end": {
+      "file": "a.go",
+      "line": 4,
+      "column": 2
+    }
+  }
+]
+`},
+		{"suppressed", []analysis.Finding{{RuleID: "r", Suppressed: true}}, `[
+  {
+    "rule_id": "r",
+    "message": "",
+    "severity": "",
+    "pos": {
+      "file": "",
+      "line": 0,
+      "column": 0
+    },
+    "end": {
+      "file": "",
+      "line": 0,
+      "column": 0
+    },
+    "suppressed": true
+  }
+]
+`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeFindingsJSON(&out, test.findings); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.want {
+				t.Errorf("got\n%s\nwant\n%s", out.String(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+// jsonlWriter writes JSON values one per line, each with a single write so
+// that a consumer sees every line as soon as it is complete. It is safe for
+// concurrent use.
+type jsonlWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	return &jsonlWriter{enc: json.NewEncoder(w)}
+}
+
+func (w *jsonlWriter) write(v any) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(v)
+}
+
+// metricsStream is the stdout stream of -format jsonl: a line with the
+// metrics of every function as soon as it is analyzed, the file metrics of
+// -file-metrics after each file and the package aggregate last.
+var metricsStream = newJSONLWriter(os.Stdout)
+
+// streamMetrics writes v to metricsStream, exiting on failure.
+func streamMetrics(v any) {
+	if err := metricsStream.write(v); err != nil {
+		log.Fatalf("Error writing metrics: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+func TestJSONLStream(t *testing.T) {
+	defer func(format string, files bool, stream *jsonlWriter, summaries []analysis.FileMetrics) {
+		*outputFormat, *fileSummary, metricsStream, fileSummaries = format, files, stream, summaries
+	}(*outputFormat, *fileSummary, metricsStream, fileSummaries)
+	*outputFormat = "jsonl"
+	const src = "package p\n\nfunc A() {}\n\nfunc B() {\n\tif x > 0 {\n\t\ty()\n\t}\n}\n"
+	tests := []struct {
+		name        string
+		fileMetrics bool
+		want        []string // kind and name of every line
+	}{
+		{"functions", false, []string{"function A", "function B"}},
+		{"with file metrics", true, []string{"function A", "function B", "file "}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			metricsStream, *fileSummary = newJSONLWriter(&out), test.fileMetrics
+			if _, err := analyzeSource(context.Background(), "p.go", src); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+				var item struct{ Kind, Name string }
+				if err := json.Unmarshal([]byte(line), &item); err != nil {
+					t.Fatalf("line %q is not JSON: %v", line, err)
+				}
+				got = append(got, item.Kind+" "+item.Name)
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("lines %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJSONLWriterConcurrent(t *testing.T) {
+	var out bytes.Buffer
+	w := newJSONLWriter(&out)
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.write(map[string]int{"n": i}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 50 {
+		t.Fatalf("%d lines, want 50", len(lines))
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("interleaved line %q", line)
+		}
+	}
+}
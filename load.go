@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// loadMode requests everything needed to analyze functions with resolved types.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+	packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps
+
+// analyzePackages loads pattern via go/packages with type checking enabled,
+// analyzes every function declared in the matched packages and returns the
+// results. Once ctx is done no further functions are analyzed.
+func analyzePackages(ctx context.Context, pattern string) []*analysis.Result {
+	conf := &packages.Config{Mode: loadMode}
+	if *buildTags != "" {
+		conf.BuildFlags = []string{"-tags=" + *buildTags}
+	}
+	start := time.Now()
+	pkgs, err := packages.Load(conf, pattern)
+	if *profile {
+		phaseTimes["parse"] += time.Since(start)
+	}
+	if err != nil {
+		log.Fatalf("Error loading packages: %v", err)
+	}
+	// Type errors are reported but do not stop the analysis: the syntax is
+	// still available and the type information is merely incomplete.
+	packages.PrintErrors(pkgs)
+
+	opts := cliOptions()
+	var results []*analysis.Result
+	excluded := 0
+	for _, pkg := range pkgs {
+		if *outputFormat == "text" {
+			fmt.Printf("Package: %s\n", pkg.PkgPath)
+		}
+		// IgnoredFiles are the files whose build constraints do not match
+		// the current platform and -tags.
+		for _, path := range pkg.IgnoredFiles {
+			fmt.Fprintf(summaryOutput(), "Skipped (build constraints): %s\n", path)
+		}
+		for _, file := range pkg.Syntax {
+			if path := pkg.Fset.Position(file.Package).Filename; skipFile(path, file) {
+				excluded++
+				continue
+			}
+			if ctx.Err() != nil {
+				return results
+			}
+			results = append(results, analysis.AnalyzeFile(ctx, pkg.Fset, file, pkg.TypesInfo, opts)...)
+		}
+	}
+	if excluded > 0 {
+		fmt.Fprintf(summaryOutput(), "Excluded files: %d\n", excluded)
+	}
+	return results
+}
@@ -1,504 +1,353 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"go/ast"
 	"go/parser"
 	"go/token"
 	"log"
-	"regexp"
+	"os"
+	"slices"
 	"strings"
 
-	"golang.org/x/tools/go/cfg"
+	"golang.org/x/term"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
 )
 
-func printCFG(cg *cfg.CFG) {
-	for _, block := range cg.Blocks {
-		fmt.Printf("Block: %s\n", block.String())
-		for _, node := range block.Nodes {
-			switch n := node.(type) {
-			case *ast.DeclStmt:
-				printValueSpec(n.Decl.(*ast.GenDecl).Specs[0].(*ast.ValueSpec))
-			case *ast.ValueSpec:
-				printValueSpec(n)
-			case *ast.AssignStmt:
-				printAssignStmt(n)
-			case *ast.ReturnStmt:
-				printReturnStmt(n)
-			case *ast.ExprStmt:
-				printExpr(n.X)
-			case *ast.ParenExpr:
-				printExpr(n.X)
-			case *ast.IncDecStmt:
-				printIncDecStmt(n)
-			case *ast.BinaryExpr:
-				printBinaryExpr(n)
-			case *ast.CallExpr:
-				printCallExpr(n)
-			default:
-				fmt.Printf(" -> Node (Unhandled): %T\n", node)
-			}
+// outputFormats lists the values accepted by -format.
+var outputFormats = []string{"text", "sarif", "metrics-json", "jsonl", "findings-json", "tui", "ascii", "ast-json"}
+
+// defaults holds the analysis options of a run without flags.
+var defaults = analysis.DefaultOptions()
+
+var (
+	configPath       = flag.String("config", "", "read default flag values from the JSON `file`; by default the nearest "+configName+" from the analyzed directory up is used, \"none\" disables it")
+	packagePattern   = flag.String("package", "", "load `pattern` with go/packages and analyze every function with full type info")
+	buildTags        = flag.String("tags", "", "comma-separated build `tags` used to select files in -package mode")
+	excludePatterns  = flag.String("exclude", "", "comma-separated glob `patterns` of files to skip in -package mode, e.g. *_gen.go,vendor/")
+	includeGenerated = flag.Bool("generated", false, "also analyze files marked as generated code in -package mode")
+	orderNodes       = flag.Bool("order", false, "prefix node labels with their execution order")
+	outputFormat     = flag.String("format", "text", "output `format`: "+strings.Join(outputFormats, ", "))
+	chepinP          = flag.Float64("chepin-p", defaults.ChepinP, "`weight` of the input variables (P) in the Chepin score")
+	chepinM          = flag.Float64("chepin-m", defaults.ChepinM, "`weight` of the modified variables (M) in the Chepin score")
+	chepinC          = flag.Float64("chepin-c", defaults.ChepinC, "`weight` of the control variables (C) in the Chepin score")
+	chepinT          = flag.Float64("chepin-t", defaults.ChepinT, "`weight` of the unused variables (T) in the Chepin score")
+	metricList       = flag.String("metrics", "", "comma-separated `list` of the metrics to compute and print, e.g. cyclomatic,chepin (default all); an unknown name lists the valid ones")
+	metricsOnly      = flag.Bool("metrics-only", false, "compute the metrics without building the printed CFG, AST dump or DOT graphs")
+	blockNodes       = flag.Bool("block-nodes", false, "draw each basic block as one record node listing its statements, with edges between blocks")
+	initializers     = flag.Bool("initializers", false, "also analyze package-level var initializers that contain calls or function literals")
+	branchLabels     = flag.Bool("branch-labels", false, "label branch edges with the outcome taking them (true, false, case values) instead of the successor block kind")
+	reportUnhandled  = flag.Bool("unhandled", false, "write the AST node types that had no rendering, with counts and example positions, as JSON to stderr")
+	exportedOnly     = flag.Bool("exported-only", false, "only analyze exported functions and methods of exported types")
+	minLines         = flag.Int("min-lines", 0, "skip functions spanning fewer than `n` lines")
+	showDominators   = flag.Bool("dominators", false, "compute the immediate dominator of every block and report it in the text and metrics-json output")
+	callGraph        = flag.String("callgraph", "", "write the DOT call graph between the analyzed functions, resolved by name, to `file` (- for stdout)")
+	clip             = flag.Bool("clip", false, "copy the DOT graphs, joined into one graph with a cluster per function, to the system clipboard, e.g. for pasting into Graphviz Online")
+	drawDefUse       = flag.Bool("def-use", false, "draw data-flow edges from each definition to the uses it reaches, merging definitions at join points, instead of linking occurrences in order")
+	maxSelector      = flag.Int("max-selector", 0, "abbreviate selector chains of more than `n` names, e.g. a.b.c.d.e to a...e, with the full chain in the node's tooltip; 0 keeps them whole")
+	fileSummary      = flag.Bool("file-metrics", false, "also report per-file totals of decision points, statements and variables, with the counts of package-level declarations")
+	defUseLabels     = flag.Bool("def-use-labels", false, "append the variables each node defines and uses to its label, as def={...} and use={...}")
+	htmlLabels       = flag.Bool("html-labels", false, "write node labels as Graphviz HTML-like labels with keywords, identifiers and literals colored")
+	colorSchemeName  = flag.String("color-scheme", defaults.ColorScheme, "`scheme` for edge colors and node fills: "+strings.Join(analysis.ColorSchemes, ", "))
+	probabilities    = flag.Bool("probabilities", false, "draw edges with a width and label reflecting a heuristic estimate of how often the branch is taken; see branchProbability")
+	ifEdges          = flag.String("if-edges", defaults.IfEdges, "how the branches of an if are drawn: "+strings.Join(analysis.IfRenderings, ", "))
+	maxComplexity    = flag.Int("max-complexity", defaults.MaxComplexity, "report functions whose cyclomatic complexity exceeds `n` (0 disables)")
+	maxStatements    = flag.Int("max-statements", defaults.MaxStatements, "report functions with more than `n` statements (0 disables)")
+	snippet          = flag.String("e", "", "analyze the statements in `code` wrapped in a function instead of the sample program")
+	splitDir         = flag.String("split", "", "also write each function's graph split into per-loop and per-branch sub-graphs to `dir`")
+	validate         = flag.Bool("validate", false, "only check that every CFG node of every function has a rendering, listing the node types that do not; exits with status 1 if any")
+	lineTarget       = flag.String("line", "", "analyze only the function declared around `[file:]line`, such as the one under an editor's cursor")
+	dbPath           = flag.String("db", "", "append the metrics of every function to the SQLite database `file`, with the run time and git commit")
+	baselineMode     = flag.String("baseline", "", "`mode` write or check: store metrics in, or compare them against, the baseline file given as argument")
+	baselineDelta    = flag.Float64("baseline-delta", 0, "allowed growth of a metric over the baseline before it is reported")
+	stdinBatch       = flag.Bool("stdin-batch", false, "read a JSON array of {filename, content} objects from stdin and write a JSON array of per-file reports; see batch.go")
+	timeout          = flag.Duration("timeout", 0, "stop the analysis after `duration` and report the partial results (0 disables)")
+	showSummary      = flag.Bool("summary", false, "print a footer with complexity totals and averages, the line count and the Chepin score distribution of all analyzed functions")
+	profile          = flag.Bool("profile", false, "report the time spent parsing, building CFGs, generating DOT and computing metrics")
+	diffSource       = flag.String("diff", "", "only analyze functions changed by the unified diff read from `source`: a file, - for stdin, git or git:<rev>")
+)
+
+func main() {
+	flag.Parse()
+	if *configPath == "" {
+		*configPath = findConfig(configDir())
+	}
+	if *configPath != "" && *configPath != "none" {
+		if err := applyConfig(*configPath); err != nil {
+			log.Fatalf("Error reading configuration: %v", err)
 		}
-		for _, succ := range block.Succs {
-			fmt.Printf(" -> Successor: %s\n", succ.String())
+	}
+	if !slices.Contains(outputFormats, *outputFormat) {
+		log.Fatalf("Unknown output format %q", *outputFormat)
+	}
+	// The interactive browser needs a terminal; fall back to plain output
+	// when stdout is redirected.
+	if *outputFormat == "tui" && !term.IsTerminal(int(os.Stdout.Fd())) {
+		*outputFormat = "text"
+	}
+	// The batch report is the only output on stdout.
+	if *stdinBatch {
+		*outputFormat = "metrics-json"
+	}
+	// Validation only renders the labels, and its verdict is the only
+	// output.
+	if *validate {
+		*outputFormat = "metrics-json"
+		*metricsOnly = true
+	}
+	if !slices.Contains(analysis.IfRenderings, *ifEdges) {
+		log.Fatalf("Unknown if rendering %q", *ifEdges)
+	}
+	if !slices.Contains(analysis.ColorSchemes, *colorSchemeName) {
+		log.Fatalf("Unknown color scheme %q", *colorSchemeName)
+	}
+	if *metricList != "" {
+		selected, err := parseMetricSelection(*metricList)
+		if err != nil {
+			log.Fatalf("Invalid -metrics: %v", err)
 		}
+		selectedMetrics = selected
 	}
-}
-
-func printValueSpec(valueSpec *ast.ValueSpec) {
-	for i, name := range valueSpec.Names {
-		value := "nil"
-		if i < len(valueSpec.Values) {
-			value = getValue(valueSpec.Values[i])
+	if *lineTarget != "" {
+		file, line, err := parseLineTarget(*lineTarget)
+		if err != nil {
+			log.Fatalf("Invalid -line: %v", err)
 		}
-		fmt.Printf(" -> Node: %s = %s\n", name.Name, value)
+		targetFile, targetLine = file, line
 	}
-}
-
-func printAssignStmt(assignStmt *ast.AssignStmt) {
-	for i, lhs := range assignStmt.Lhs {
-		if ident, ok := lhs.(*ast.Ident); ok {
-			value := "nil"
-			if i < len(assignStmt.Rhs) {
-				value = getValue(assignStmt.Rhs[i])
-			}
-			fmt.Printf(" -> Node: %s = %s\n", ident.Name, value)
+	if *diffSource != "" {
+		ranges, err := loadDiff(*diffSource)
+		if err != nil {
+			log.Fatalf("Error reading diff: %v", err)
 		}
+		changedLines = ranges
 	}
-}
 
-func printReturnStmt(returnStmt *ast.ReturnStmt) {
-	values := []string{}
-	for _, result := range returnStmt.Results {
-		values = append(values, getValue(result))
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
 	}
-	fmt.Printf(" -> Return: %s\n", values)
-}
 
-func printExpr(expr ast.Expr) {
-	switch e := expr.(type) {
-	case *ast.BinaryExpr:
-		printBinaryExpr(e)
-	case *ast.CallExpr:
-		printCallExpr(e)
-	case *ast.ParenExpr:
-		fmt.Printf(" -> Node: (%s)\n", getValue(e.X))
+	var results []*analysis.Result
+	var err error
+	switch {
+	case *stdinBatch:
+		results, err = runBatch(ctx, os.Stdin, os.Stdout)
+		if err != nil {
+			log.Fatalf("Error running batch: %v", err)
+		}
+	case *packagePattern != "":
+		results = analyzePackages(ctx, *packagePattern)
+	case *snippet != "":
+		results, err = analyzeSource(ctx, "snippet.go", analysis.SnippetSource(*snippet))
 	default:
-		fmt.Printf(" -> Node (Unhandled Expr): %T\n", expr)
+		results, err = analyzeSource(ctx, "example.go", analysis.SampleSource)
 	}
-}
-
-func printIncDecStmt(incDecStmt *ast.IncDecStmt) {
-	fmt.Printf(" -> Node: %s %s\n", incDecStmt.X.(*ast.Ident).Name, incDecStmt.Tok.String())
-}
-
-func printBinaryExpr(binaryExpr *ast.BinaryExpr) {
-	fmt.Printf(" -> Node: %s %s %s\n", getValue(binaryExpr.X), binaryExpr.Op.String(), getValue(binaryExpr.Y))
-}
-
-func printCallExpr(callExpr *ast.CallExpr) {
-	funcName := getValue(callExpr.Fun)
-	args := []string{}
-	for _, arg := range callExpr.Args {
-		args = append(args, getValue(arg))
+	if err != nil {
+		log.Fatalf("Error parsing source code: %v", err)
 	}
-	fmt.Printf(" -> Node: %s(%s)\n", funcName, args)
-}
-
-func getValue(expr ast.Expr) string {
-	switch e := expr.(type) {
-	case *ast.BasicLit:
-		return e.Value
-	case *ast.Ident:
-		return e.Name
-	case *ast.BinaryExpr:
-		return fmt.Sprintf("%s %s %s", getValue(e.X), e.Op.String(), getValue(e.Y))
-	case *ast.CallExpr:
-		if ident, ok := e.Fun.(*ast.Ident); ok {
-			return ident.Name
-		}
-		return "function call"
-	case *ast.SelectorExpr:
-		return fmt.Sprintf("%s.%s", getValue(e.X), e.Sel.Name)
-	default:
-		return fmt.Sprintf("%T", expr)
+	if targetLine > 0 && targetMatches == 0 {
+		log.Fatalf("No function contains line %s", *lineTarget)
 	}
-}
-
-func genDot(cg *cfg.CFG) string {
-	// CHEPIN
-	var P, M, C, T int
-	// Sets to keep track of variables
-	inputVars := make(map[string]int)     // P
-	modifiedVars := make(map[string]bool) // M
-	controlVars := make(map[string]bool)  // C
-	unusedVars := make(map[string]bool)   // T
-
-	dot := "digraph G {\n"
-	variables := make(map[string][]string)
-	for _, block := range cg.Blocks {
-		if !block.Live {
-			continue
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Warning: analysis timed out after %s; the results are partial\n", *timeout)
+	}
+	var findings []analysis.Finding
+	for _, result := range results {
+		findings = append(findings, result.Findings...)
+	}
+	switch *outputFormat {
+	case "sarif":
+		if err := writeSARIF(os.Stdout, findings); err != nil {
+			log.Fatalf("Error writing SARIF: %v", err)
 		}
-		blockID := fmt.Sprintf("block_%d", block.Index)
-		// DEBUG
-		//blockLabel := block.String()
-		//dot += fmt.Sprintf("  %s [label=\"%s\"];\n", blockID, blockLabel)
-		var prevNodeID string
-		var lastNodeID string
-		var loopID string
-		// DEBUG basic blocks
-		/*		if len(block.Nodes) == 0 {
-				for _, succ := range block.Succs {
-					succID := fmt.Sprintf("block_%d", succ.Index)
-					color := "black"
-					if succ.Kind == cfg.KindIfThen || succ.Kind == cfg.KindForBody {
-						color = "yellow"
-					} else if succ.Kind == cfg.KindIfDone || succ.Kind == cfg.KindIfElse || succ.Kind == cfg.KindForDone {
-						color = "red"
-					}
-					dot += fmt.Sprintf("  %s -> %s [color=\"%s\"];\n", blockID, succID, color)
-				}
-			} */
-		for i, node := range block.Nodes {
-			nodeID := fmt.Sprintf("%s_node_%d", blockID, i)
-			switch n := node.(type) {
-			case *ast.ValueSpec:
-				for i, name := range n.Names {
-					value := "nul"
-					if i < len(n.Values) {
-						value = getValue(n.Values[i])
-					}
-					dot += fmt.Sprintf("  %s [label=\"%s = %s\"];\n", nodeID, name.Name, value)
-					variables[name.Name] = append(variables[name.Name], nodeID)
-					inputVars[name.Name]++
-				}
-
-			case *ast.DeclStmt:
-				valueSpec := n.Decl.(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
-				for j, name := range valueSpec.Names {
-					value := "nil"
-					if j < len(valueSpec.Values) {
-						value = getValue(valueSpec.Values[j])
-					}
-					dot += fmt.Sprintf("  %s [label=\"%s = %s\"];\n", nodeID, name.Name, value)
-					variables[name.Name] = append(variables[name.Name], nodeID)
-					inputVars[name.Name]++
-				}
-			case *ast.AssignStmt:
-				for j, lhs := range n.Lhs {
-					if ident, ok := lhs.(*ast.Ident); ok {
-						value := "nil"
-						if j < len(n.Rhs) {
-							value = getValue(n.Rhs[j])
-						}
-						dot += fmt.Sprintf("  %s [label=\"%s = %s\"];\n", nodeID, ident.Name, value)
-						variables[ident.Name] = append(variables[ident.Name], nodeID)
-						inputVars[ident.Name]++
-						if _, isBinaryExpr := n.Rhs[j].(*ast.BinaryExpr); isBinaryExpr {
-							modifiedVars[ident.Name] = true
-						}
-					}
-				}
-			case *ast.ReturnStmt:
-				values := []string{}
-				for _, result := range n.Results {
-					value := getValue(result)
-					values = append(values, value)
-					variables[value] = append(variables[value], nodeID)
-				}
-				dot += fmt.Sprintf("  %s [label=\"Return: %s\"];\n", nodeID, strings.Join(values, ", "))
-			case *ast.ExprStmt:
-				switch e := n.X.(type) {
-				case *ast.BinaryExpr:
-					dot += fmt.Sprintf("  %s [label=\"%s %s %s\"];\n", nodeID, getValue(e.X), e.Op.String(), getValue(e.Y))
-				case *ast.CallExpr:
-					funcName := getValue(e.Fun)
-					args := []string{}
-					for _, arg := range e.Args {
-						args = append(args, getValue(arg))
-					}
-					label := fmt.Sprintf("%s(%s)", funcName, strings.Join(args, ", "))
-					label = strings.ReplaceAll(label, `"`, `\"`) // escape double quotes
-					dot += fmt.Sprintf("  %s [label=\"%s\"];\n", nodeID, label)
-				default:
-					dot += fmt.Sprintf("  %s [label=\"(Unhandled Expr): %T\"];\n", nodeID, n.X)
-				}
-			case *ast.IncDecStmt:
-				varName := n.X.(*ast.Ident).Name
-				dot += fmt.Sprintf("  %s [label=\"%s %s\"];\n", nodeID, n.X.(*ast.Ident).Name, n.Tok.String())
-				variables[varName] = append(variables[varName], nodeID)
-				modifiedVars[varName] = true
-			case *ast.BinaryExpr:
-				dot += fmt.Sprintf("  %s [label=\"%s %s %s\"];\n", nodeID, getValue(n.X), n.Op.String(), getValue(n.Y))
-				controlVars[getValue(n.X)] = true
-				controlVars[getValue(n.Y)] = true
-			case *ast.CallExpr:
-				funcName := getValue(n.Fun)
-				args := []string{}
-				for _, arg := range n.Args {
-					args = append(args, getValue(arg))
-				}
-				dot += fmt.Sprintf("  %s [label=\"%s(%s)\"];\n", nodeID, funcName, strings.Join(args, ", "))
-			case *ast.SelectorExpr:
-				dot += fmt.Sprintf("  %s [label=\"%s.%s\"];\n", nodeID, getValue(n.X), n.Sel.Name)
-			case *ast.ParenExpr:
-				if binaryExpr, ok := n.X.(*ast.BinaryExpr); ok {
-					dot += fmt.Sprintf("  %s [label=\"%s %s %s\"];\n", nodeID, getValue(binaryExpr.X), binaryExpr.Op.String(), getValue(binaryExpr.Y))
-				} else {
-					dot += fmt.Sprintf("  %s [label=\"%s\"];\n", nodeID, getValue(n.X))
-				}
-			case *ast.IfStmt:
-				cond := getValue(n.Cond)
-				dot += fmt.Sprintf("  %s [label=\"if %s\"];\n", nodeID, cond)
-
-				controlVars[getValue(n.Cond)] = true
-
-				thenBlockID := fmt.Sprintf("block_%d", block.Succs[0].Index)
-				thenBlockLabel := cg.Blocks[block.Succs[0].Index].String()
-				dot += fmt.Sprintf("  %s -> %s [label=\"%s\" color=\"yellow\"];\n", nodeID, thenBlockID, thenBlockLabel)
-				if n.Else != nil {
-					elseBlockID := fmt.Sprintf("block_%d", block.Succs[1].Index)
-					elseBlockLabel := cg.Blocks[block.Succs[1].Index].String()
-					dot += fmt.Sprintf("  %s -> %s [label=\"%s\" color=\"red\"];\n", nodeID, elseBlockID, elseBlockLabel)
-				}
-			case *ast.ForStmt:
-				loopID = nodeID
-				cond := getValue(n.Cond)
-				dot += fmt.Sprintf("  %s [label=\"for %s\"];\n", nodeID, cond)
-
-				controlVars[getValue(n.Cond)] = true
-
-				bodyBlockID := fmt.Sprintf("block_%d", block.Succs[0].Index)
-				bodyBlockLabel := cg.Blocks[block.Succs[0].Index].String()
-				dot += fmt.Sprintf("  %s -> %s [label=\"%s\" color=\"yellow\"];\n", nodeID, bodyBlockID, bodyBlockLabel)
-				postBlockID := fmt.Sprintf("block_%d", block.Succs[1].Index)
-				postBlockLabel := cg.Blocks[block.Succs[1].Index].String()
-				dot += fmt.Sprintf("  %s -> %s [label=\"%s\" color=\"red\"];\n", nodeID, postBlockID, postBlockLabel)
-			case *ast.BranchStmt:
-				// Handle BranchStmt nodes differently
-				switch n.Tok {
-				case token.CONTINUE:
-					dot += fmt.Sprintf("  %s [label=\"continue\"];\n", nodeID)
-					dot += fmt.Sprintf("  %s -> %s [label=\"continue\"];\n", nodeID, loopID)
-				case token.BREAK:
-					dot += fmt.Sprintf("  %s [label=\"break\"];\n", nodeID)
-					dot += fmt.Sprintf("  %s -> %s [label=\"break\"];\n", nodeID, loopID)
-				}
-			default:
-				fmt.Printf("Node type: %T ==> %s\n", node, nodeID) // debugging statement
-				dot += fmt.Sprintf("  %s [label=\"(Unhandled): %T\"];\n", nodeID, node)
-			}
-			if prevNodeID != "" {
-				dot += fmt.Sprintf("  %s -> %s;\n", prevNodeID, nodeID)
-			}
-			// DEBUG
-			/* else {
-				dot += fmt.Sprintf("  %s -> %s;\n", blockID, nodeID)
-			} */
-			prevNodeID = nodeID
-			lastNodeID = nodeID
+	case "metrics-json":
+		if *stdinBatch {
+			// runBatch has written the per-file reports.
+			break
 		}
-		for _, succ := range block.Succs {
-			succID := fmt.Sprintf("block_%d", succ.Index)
-			//fmt.Printf("Block type: %s %d\n", succ.Kind, succ.Index) // debugging statement
-			color := "black"
-			if succ.Kind == cfg.KindIfThen || succ.Kind == cfg.KindForBody {
-				color = "yellow"
-			} else if succ.Kind == cfg.KindIfDone || succ.Kind == cfg.KindIfElse || succ.Kind == cfg.KindForDone {
-				color = "red"
-			}
-
-			if lastNodeID == "" {
-				continue
-			}
-
-			// Check if the successor block has nodes
-			succBlock := cg.Blocks[succ.Index]
-			if len(succBlock.Nodes) > 0 {
-				firstSuccNodeID := fmt.Sprintf("block_%d_node_0", succ.Index)
-				succBlockLabel := succBlock.String()
-				if strings.Contains(succBlockLabel, "(IfDone)") || strings.Contains(succBlockLabel, "(IfThen)") || strings.Contains(succBlockLabel, "(For") {
-					dot += fmt.Sprintf("  %s -> %s [color=\"%s\" label=\"%s\" fontsize=14 decorate=true];\n", lastNodeID, firstSuccNodeID, color, succBlockLabel)
-				} else {
-					dot += fmt.Sprintf("  %s -> %s [color=\"%s\"];\n", lastNodeID, firstSuccNodeID, color)
-				}
-			} else {
-				// If the successor block does not have nodes, find the next block with nodes
-				nextBlockWithNodes := findNextBlockWithNodes(cg, int(succ.Index))
-				if nextBlockWithNodes != nil {
-					firstSuccNodeID := fmt.Sprintf("block_%d_node_0", nextBlockWithNodes.Index)
-					succBlockLabel := nextBlockWithNodes.String()
-					if strings.Contains(succBlockLabel, "(IfDone)") || strings.Contains(succBlockLabel, "(IfThen)") || strings.Contains(succBlockLabel, "(For") {
-						dot += fmt.Sprintf("  %s -> %s [color=\"%s\" label=\"%s\" fontsize=14 decorate=true];\n", lastNodeID, firstSuccNodeID, color, succBlockLabel)
-					} else {
-						dot += fmt.Sprintf("  %s -> %s [color=\"%s\"];\n", lastNodeID, firstSuccNodeID, color)
-					}
-				} else {
-					dot += fmt.Sprintf("  %s -> %s [color=\"%s\"];\n", lastNodeID, succID, color)
-				}
+		if *validate {
+			if !printValidation(os.Stdout, len(results)) {
+				os.Exit(1)
 			}
+			return
 		}
-	}
-	for varName, nodes := range variables {
-		if len(nodes) > 1 {
-			for i := 1; i < len(nodes); i++ {
-				dot += fmt.Sprintf("  %s -> %s [label=\"%s\" style=dotted fontsize=26];\n", nodes[i-1], nodes[i], varName)
-			}
+		if err := writeMetricsJSON(os.Stdout, results); err != nil {
+			log.Fatalf("Error writing metrics: %v", err)
+		}
+	case "jsonl":
+		// The functions have been written as they were analyzed.
+		streamMetrics(aggregate(results))
+	case "findings-json":
+		if err := writeFindingsJSON(os.Stdout, findings); err != nil {
+			log.Fatalf("Error writing findings: %v", err)
+		}
+	case "ast-json":
+		if err := writeASTJSON(os.Stdout, results); err != nil {
+			log.Fatalf("Error writing AST: %v", err)
+		}
+	case "tui":
+		if err := runTUI(results); err != nil {
+			log.Fatalf("Error running the interactive browser: %v", err)
 		}
 	}
-
-	// Determine unused variables
-	/*	for varName := range inputVars {
-		if !modifiedVars[varName] && !controlVars[varName] {
-			unusedVars[varName] = true
+	if shortFuncs > 0 {
+		fmt.Fprintf(summaryOutput(), "Skipped (shorter than %d lines): %d\n", *minLines, shortFuncs)
+	}
+	if unexportedFuncs > 0 {
+		fmt.Fprintf(summaryOutput(), "Skipped (unexported): %d\n", unexportedFuncs)
+	}
+	if *clip {
+		clipResults(results)
+	}
+	if *callGraph != "" {
+		if err := writeCallGraph(*callGraph, results); err != nil {
+			log.Fatalf("Error writing call graph: %v", err)
 		}
-	} */
-
-	// Remove intersections between sets
-	for varName := range controlVars {
-		delete(inputVars, varName)
-		delete(modifiedVars, varName)
 	}
-	for varName := range modifiedVars {
-		delete(inputVars, varName)
+	if suppressed := analysis.SuppressedCount(findings); suppressed > 0 {
+		fmt.Fprintf(summaryOutput(), "Suppressed findings: %d\n", suppressed)
 	}
-
-	for varName := range inputVars {
-		if inputVars[varName] == 1 {
-			unusedVars[varName] = true
-			delete(inputVars, varName)
+	if *showSummary {
+		printSummary(summaryOutput(), results)
+	}
+	if *profile {
+		printProfile(summaryOutput())
+	}
+	if *reportUnhandled {
+		if err := writeUnhandled(os.Stderr); err != nil {
+			log.Fatalf("Error writing unhandled node types: %v", err)
 		}
 	}
-
-	// Calculate the sizes of the sets
-	P = len(inputVars)
-	M = len(modifiedVars)
-	C = len(controlVars)
-	T = len(unusedVars)
-
-	// Calculate Chepin metric
-	Q := float64(P) + 2*float64(M) + 3*float64(C) + 0.5*float64(T)
-	fmt.Println(strings.Repeat("-", 18))
-	fmt.Println("P: ", inputVars)
-	fmt.Println("M: ", modifiedVars)
-	fmt.Println("C: ", controlVars)
-	fmt.Println("T: ", unusedVars)
-	fmt.Println("Chepin score: ", Q)
-	//fmt.Println("Variables list: ", variables)
-
-	// Calculate cyclomatic complexity
-	numEdges := 0
-	numNodes := 0
-	for _, block := range cg.Blocks {
-		if block.Live {
-			numNodes++
-			numEdges += len(block.Succs)
+	if *dbPath != "" {
+		if err := writeDB(*dbPath, results); err != nil {
+			log.Fatalf("Error writing database: %v", err)
 		}
 	}
-	cyclomaticComplexity := numEdges - numNodes + 2
-	fmt.Println(strings.Repeat("-", 18))
-	fmt.Println("Cyclomatic Complexity: ", cyclomaticComplexity)
-	fmt.Printf("Number of Edges: %d.\n", numEdges)
-	fmt.Printf("Number of Nodes: %d.\n", numNodes)
-
-	re := regexp.MustCompile(`label="block \d+ ([^"]+)"`)
-	dot = re.ReplaceAllString(dot, `label="$1"`)
-
-	dot += "}\n"
-
-	return dot
+	if *baselineMode != "" {
+		runBaseline(*baselineMode, flag.Arg(0), results)
+	}
 }
 
-func findNextBlockWithNodes(cg *cfg.CFG, startIndex int) *cfg.Block {
-	visited := make(map[int]bool)
-	queue := []int{startIndex}
-
-	for len(queue) > 0 {
-		currentIndex := queue[0]
-		queue = queue[1:]
-		visited[currentIndex] = true
-
-		currentBlock := cg.Blocks[currentIndex]
-		if len(currentBlock.Nodes) > 0 {
-			return currentBlock
+// runBaseline writes or checks the baseline file path. A failed check exits
+// with status 1 so the baseline works as a ratchet in CI.
+func runBaseline(mode, path string, results []*analysis.Result) {
+	if path == "" {
+		log.Fatalf("-baseline %s needs a file argument", mode)
+	}
+	switch mode {
+	case "write":
+		if err := writeBaseline(path, results); err != nil {
+			log.Fatalf("Error writing baseline: %v", err)
 		}
-
-		for _, succ := range currentBlock.Succs {
-			if !visited[int(succ.Index)] {
-				queue = append(queue, int(succ.Index))
-			}
+	case "check":
+		baseline, err := readBaseline(path)
+		if err != nil {
+			log.Fatalf("Error reading baseline: %v", err)
+		}
+		if checkBaseline(summaryOutput(), baseline, results, *baselineDelta) > 0 {
+			os.Exit(1)
 		}
+	default:
+		log.Fatalf("Unknown baseline mode %q: want write or check", mode)
 	}
-
-	return nil
 }
 
-func main() {
-	src := `
-package main
-
-func complexFunction() int {
-	a := 0
-	b := 1
-	c := 3
-	n := 4
-	result := 0
-	sum := 0
-
-	for i := 0; i < n; i++ {
-		if с > 2 {
-			a += i
-		} else {
-			b += i
-		}
+// summaryOutput returns where run summaries are written: standard output in
+// text mode and standard error otherwise, so machine-readable output stays
+// clean.
+func summaryOutput() *os.File {
+	if *outputFormat != "text" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
 
-		for j := 0; j < i; j++ {
-			if j < 3 {
-				c += j
-			} else {
-				sum += j
+// cliOptions returns the analysis options set by the flags.
+func cliOptions() analysis.Options {
+	opts := analysis.Options{
+		Metrics:       selectedMetrics,
+		ChepinP:       *chepinP,
+		ChepinM:       *chepinM,
+		ChepinC:       *chepinC,
+		ChepinT:       *chepinT,
+		Dominators:    *showDominators,
+		MaxComplexity: *maxComplexity,
+		MaxStatements: *maxStatements,
+		Initializers:  *initializers,
+		Select:        selectFunc,
+		MetricsOnly:   *metricsOnly,
+		OrderNodes:    *orderNodes,
+		BlockNodes:    *blockNodes,
+		BranchLabels:  *branchLabels,
+		DefUse:        *drawDefUse,
+		DefUseLabels:  *defUseLabels,
+		HTMLLabels:    *htmlLabels,
+		Probabilities: *probabilities,
+		MaxSelector:   *maxSelector,
+		ColorScheme:   *colorSchemeName,
+		IfEdges:       *ifEdges,
+		AST:           *outputFormat == "ast-json",
+		ASCII:         *outputFormat == "ascii",
+		Split:         *splitDir != "",
+		Validate:      *validate,
+		Log:           os.Stderr,
+		OnResult:      handleResult,
+	}
+	if *outputFormat == "text" {
+		opts.Text = os.Stdout
+	}
+	if *fileSummary {
+		opts.OnFile = func(m analysis.FileMetrics) {
+			fileSummaries = append(fileSummaries, m)
+			if *outputFormat == "jsonl" {
+				streamMetrics(m)
 			}
 		}
-
-		if a > b {
-			continue
-		} else if b > c {
-			break
-		}
 	}
-	if sum > 10 {
-		result = a + b
-		return result
-	} else {
-		return c
+	if *reportUnhandled || *validate {
+		opts.Unhandled = unhandledNodes
 	}
+	if *profile {
+		opts.Profile = phaseTimes
+	}
+	return opts
 }
-`
-	fset := token.NewFileSet()
 
-	node, err := parser.ParseFile(fset, "example.go", src, parser.Trace)
-	if err != nil {
-		log.Fatalf("Error parsing source code: %v", err)
+// handleResult writes the output of a function that is not deferred to the
+// end of the run: its line of -format jsonl, its -format ascii rendering and
+// its -split graphs.
+func handleResult(result *analysis.Result) {
+	switch *outputFormat {
+	case "jsonl":
+		item, err := selectedFields(result.Metrics)
+		if err != nil {
+			log.Fatalf("Error writing metrics: %v", err)
+		}
+		streamMetrics(item)
+	case "ascii":
+		fmt.Printf("CFG for function: %s\n", result.Metrics.Name)
+		fmt.Println(result.ASCII)
 	}
-
-	ast.Print(fset, node)
-
-	fmt.Print("\n-------------------\n")
-	for _, decl := range node.Decls {
-		if fn, ok := decl.(*ast.FuncDecl); ok {
-			if fn.Body != nil {
-				predicate := func(*ast.CallExpr) bool { return true }
-				cg := cfg.New(fn.Body, predicate)
-				fmt.Printf("CFG for function: %s\n", fn.Name.Name)
-
-				printCFG(cg)
-
-				dotFmt := genDot(cg)
-				fmt.Println(strings.Repeat("-", 18))
-				fmt.Println("DOT Format:")
-				fmt.Println(dotFmt)
-			}
+	if result.SplitGraphs != nil {
+		if err := writeSplitGraphs(*splitDir, result.SplitGraphs); err != nil {
+			log.Fatalf("Error writing split graphs: %v", err)
 		}
 	}
 }
+
+// analyzeSource parses src as the file filename and analyzes its functions
+// with the options set by the flags until ctx is done. In text mode the
+// report starts with the parser's trace.
+func analyzeSource(ctx context.Context, filename, src string) ([]*analysis.Result, error) {
+	opts := cliOptions()
+	if opts.Text != nil && !opts.MetricsOnly {
+		// The errors, if any, are reported by the analysis.
+		parser.ParseFile(token.NewFileSet(), filename, src, parser.Trace|parser.ParseComments|parser.AllErrors)
+	}
+	return analysis.AnalyzeSource(ctx, filename, src, opts)
+}
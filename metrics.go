@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// fileSummaries collects the file metrics of the run in analysis order.
+var fileSummaries []analysis.FileMetrics
+
+// aggregateMetrics summarizes the metrics of all analyzed functions. It is
+// the last element of the -format metrics-json array.
+type aggregateMetrics struct {
+	Kind            string  `json:"kind"` // always "package"
+	Functions       int     `json:"functions"`
+	Lines           int     `json:"lines"`
+	CyclomaticTotal int     `json:"cyclomatic_total"`
+	CyclomaticMax   int     `json:"cyclomatic_max"`
+	CognitiveTotal  int     `json:"cognitive_total"`
+	CognitiveMax    int     `json:"cognitive_max"`
+	ChepinTotal     float64 `json:"chepin_total"`
+}
+
+func aggregate(results []*analysis.Result) aggregateMetrics {
+	agg := aggregateMetrics{Kind: "package", Functions: len(results)}
+	for _, result := range results {
+		m := result.Metrics
+		agg.Lines += m.Lines
+		agg.CyclomaticTotal += m.Cyclomatic
+		agg.CyclomaticMax = max(agg.CyclomaticMax, m.Cyclomatic)
+		agg.CognitiveTotal += m.Cognitive
+		agg.CognitiveMax = max(agg.CognitiveMax, m.Cognitive)
+		agg.ChepinTotal += m.Chepin
+	}
+	return agg
+}
+
+// writeMetricsJSON writes the metrics of every result followed by their
+// aggregate as a JSON array.
+func writeMetricsJSON(w io.Writer, results []*analysis.Result) error {
+	items := []any{}
+	for _, result := range results {
+		item, err := selectedFields(result.Metrics)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	for _, file := range fileSummaries {
+		items = append(items, file)
+	}
+	items = append(items, aggregate(results))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
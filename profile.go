@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// phaseTimes accumulates the time spent in each phase for -profile.
+var phaseTimes = analysis.Profile{}
+
+// printProfile writes the per-phase breakdown collected in phaseTimes.
+func printProfile(w io.Writer) {
+	var total time.Duration
+	for _, phase := range analysis.Phases {
+		total += phaseTimes[phase]
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintln(w, "Profile:")
+	for _, phase := range analysis.Phases {
+		fmt.Fprintf(w, "  %-8s %12s\n", phase, phaseTimes[phase])
+	}
+	fmt.Fprintf(w, "  %-8s %12s\n", "total", total)
+}
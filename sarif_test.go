@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	findings := []analysis.Finding{
+		{
+			RuleID:   "duplicate-condition",
+			Message:  "condition repeated",
+			Severity: "warning",
+			Pos:      token.Position{Filename: "dir/a.go", Line: 3, Column: 12},
+			End:      token.Position{Filename: "dir/a.go", Line: 3, Column: 17},
+		},
+		{RuleID: "empty-branch", Severity: "note", Pos: token.Position{Filename: "b.go", Line: 7}, Suppressed: true},
+	}
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, findings); err != nil {
+		t.Fatal(err)
+	}
+	var doc sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if doc.Version != "2.1.0" || len(doc.Runs) != 1 {
+		t.Fatalf("version %q with %d runs, want 2.1.0 with 1", doc.Version, len(doc.Runs))
+	}
+	run := doc.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(analysis.Rules) {
+		t.Errorf("%d rules, want %d", len(run.Tool.Driver.Rules), len(analysis.Rules))
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("%d results, want 2", len(run.Results))
+	}
+	first := run.Results[0]
+	want := sarifRegion{StartLine: 3, StartColumn: 12, EndLine: 3, EndColumn: 17}
+	if first.RuleID != "duplicate-condition" || first.Level != "warning" || first.Locations[0].PhysicalLocation.Region != want {
+		t.Errorf("first result %+v", first)
+	}
+	if uri := first.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "dir/a.go" {
+		t.Errorf("URI %q, want dir/a.go", uri)
+	}
+	if first.Suppressions != nil {
+		t.Errorf("unsuppressed finding has suppressions %v", first.Suppressions)
+	}
+	if s := run.Results[1].Suppressions; len(s) != 1 || s[0].Kind != "inSource" {
+		t.Errorf("suppressed finding has suppressions %v, want one inSource", s)
+	}
+}
+
+// TestSARIFSchema analyzes a source with findings of the data-flow and
+// complexity rules and validates the SARIF written for them against the
+// schema of the properties writeSARIF emits.
+func TestSARIFSchema(t *testing.T) {
+	defer func(format string) { *outputFormat = format }(*outputFormat)
+	defer func(n int) { *maxComplexity = n }(*maxComplexity)
+	*outputFormat = "metrics-json"
+	*maxComplexity = 2
+	const src = `package p
+
+var y int
+
+func f(a int) int {
+	x := 1
+	x = a
+	if a > 0 {
+		y := 2
+		x += y
+	}
+	if a > 1 {
+		return y
+	}
+	return x
+}
+
+//cfg:ignore dead-store
+func g() int {
+	z := 1
+	z = 2
+	return 0
+}
+`
+	results, err := analyzeSource(context.Background(), "dir/p.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var findings []analysis.Finding
+	for _, result := range results {
+		findings = append(findings, result.Findings...)
+	}
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, findings); err != nil {
+		t.Fatal(err)
+	}
+	var doc any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	schemaSrc, err := os.ReadFile("testdata/sarif-schema-2.1.0-subset.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(schemaSrc, &schema); err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range validateSchema(schema, schema, doc, "") {
+		t.Error(err)
+	}
+	// The validator itself must catch a broken result.
+	result := doc.(map[string]any)["runs"].([]any)[0].(map[string]any)["results"].([]any)[0].(map[string]any)
+	result["level"] = "fatal"
+	if errs := validateSchema(schema, schema, doc, ""); len(errs) != 1 {
+		t.Errorf("result with level %q: errors %v, want one", result["level"], errs)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	var ruleIDs []string
+	for _, result := range log.Runs[0].Results {
+		if result.Suppressions == nil {
+			ruleIDs = append(ruleIDs, result.RuleID)
+		}
+	}
+	slices.Sort(ruleIDs)
+	want := []string{"cyclomatic-complexity", "dead-store", "use-before-def"}
+	if !slices.Equal(slices.Compact(ruleIDs), want) {
+		t.Errorf("results for rules %v, want %v:\n%s", ruleIDs, want, buf.String())
+	}
+}
+
+// validateSchema checks value against the JSON schema node, resolving
+// references in root, and returns one error per violation. It knows the
+// keywords the vendored SARIF schema uses and ignores the others.
+func validateSchema(root, node map[string]any, value any, path string) []error {
+	if ref, ok := node["$ref"].(string); ok {
+		def, ok := root["definitions"].(map[string]any)[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any)
+		if !ok {
+			return []error{fmt.Errorf("%s: unresolved reference %s", path, ref)}
+		}
+		return validateSchema(root, def, value, path)
+	}
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+	if typ, ok := node["type"]; ok {
+		types, ok := typ.([]any)
+		if !ok {
+			types = []any{typ}
+		}
+		if !slices.ContainsFunc(types, func(t any) bool { return schemaType(value, t.(string)) }) {
+			fail("%v is not of type %v", value, typ)
+			return errs
+		}
+	}
+	if enum, ok := node["enum"].([]any); ok && !slices.Contains(enum, value) {
+		fail("%v is not one of %v", value, enum)
+	}
+	if minimum, ok := node["minimum"].(float64); ok {
+		if n, ok := value.(float64); ok && n < minimum {
+			fail("%v is less than %v", n, minimum)
+		}
+	}
+	if anyOf, ok := node["anyOf"].([]any); ok {
+		if !slices.ContainsFunc(anyOf, func(sub any) bool {
+			return len(validateSchema(root, sub.(map[string]any), value, path)) == 0
+		}) {
+			fail("matches none of the anyOf schemas")
+		}
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		for _, name := range asStrings(node["required"]) {
+			if _, ok := value[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		properties, _ := node["properties"].(map[string]any)
+		for _, name := range sortedKeys(value) {
+			if sub, ok := properties[name].(map[string]any); ok {
+				errs = append(errs, validateSchema(root, sub, value[name], path+"/"+name)...)
+			} else if node["additionalProperties"] == false {
+				fail("unexpected property %q", name)
+			}
+		}
+	case []any:
+		if node["uniqueItems"] == true {
+			seen := make(map[string]bool)
+			for _, item := range value {
+				key := fmt.Sprint(item)
+				if seen[key] {
+					fail("duplicate item %v", item)
+				}
+				seen[key] = true
+			}
+		}
+		if items, ok := node["items"].(map[string]any); ok {
+			for i, item := range value {
+				errs = append(errs, validateSchema(root, items, item, fmt.Sprintf("%s/%d", path, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+// schemaType reports whether a value decoded by encoding/json has the JSON
+// schema type typ.
+func schemaType(value any, typ string) bool {
+	switch value := value.(type) {
+	case nil:
+		return typ == "null"
+	case bool:
+		return typ == "boolean"
+	case float64:
+		return typ == "number" || typ == "integer" && value == float64(int64(value))
+	case string:
+		return typ == "string"
+	case []any:
+		return typ == "array"
+	case map[string]any:
+		return typ == "object"
+	}
+	return false
+}
+
+func asStrings(v any) []string {
+	var ss []string
+	items, _ := v.([]any)
+	for _, item := range items {
+		ss = append(ss, item.(string))
+	}
+	return ss
+}
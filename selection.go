@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// selectableMetrics lists the values accepted by -metrics, each with the
+// -format metrics-json fields it covers.
+var selectableMetrics = []struct {
+	name   string
+	fields []string
+}{
+	{"cyclomatic", []string{"cyclomatic", "ast_cyclomatic"}},
+	{"cognitive", []string{"cognitive"}},
+	{"chepin", []string{"chepin_p", "chepin_m", "chepin_c", "chepin_t", "chepin"}},
+	{"statements", []string{"statements", "control_flow_ratio"}},
+	{"depth", []string{"max_expr_depth"}},
+	{"operators", []string{"operators", "distinct_operators"}},
+	{"vocabulary", []string{"variables"}},
+	{"paths", []string{"basis_paths"}},
+	{"sccs", []string{"sccs", "loop_scc_sizes", "goto_loops", "irreducible_loops"}},
+	{"blocks", []string{"largest_block", "average_block_size", "empty_blocks"}},
+	{"calls", []string{"calls", "conversions"}},
+	{"recursion", []string{"recursive_calls"}},
+}
+
+// selectedMetrics holds the metrics named by -metrics; nil selects all.
+var selectedMetrics map[string]bool
+
+// parseMetricSelection parses the comma-separated -metrics list.
+func parseMetricSelection(list string) (map[string]bool, error) {
+	valid := make([]string, 0, len(selectableMetrics))
+	for _, metric := range selectableMetrics {
+		valid = append(valid, metric.name)
+	}
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(valid, name) {
+			return nil, fmt.Errorf("unknown metric %q; valid metrics are %s", name, strings.Join(valid, ", "))
+		}
+		selected[name] = true
+	}
+	return selected, nil
+}
+
+// selectedFields returns m as a JSON object without the fields of metrics
+// left out by -metrics. Unless -metrics is given, m is returned as is.
+func selectedFields(m analysis.Metrics) (any, error) {
+	if selectedMetrics == nil {
+		return m, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, metric := range selectableMetrics {
+		if !selectedMetrics[metric.name] {
+			for _, field := range metric.fields {
+				delete(fields, field)
+			}
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+func TestParseMetricSelection(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"cyclomatic", []string{"cyclomatic"}, false},
+		{"chepin, paths,chepin", []string{"chepin", "paths"}, false},
+		{"cyclomatic,halstead", nil, true},
+		{"", nil, true},
+	}
+	for _, test := range tests {
+		selected, err := parseMetricSelection(test.list)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseMetricSelection(%q) error %v, want error %v", test.list, err, test.wantErr)
+			continue
+		}
+		if got := sortedKeys(selected); !test.wantErr && !slices.Equal(got, test.want) {
+			t.Errorf("parseMetricSelection(%q) = %v, want %v", test.list, got, test.want)
+		}
+	}
+}
+
+func TestSelectedFields(t *testing.T) {
+	defer func(selected map[string]bool) { selectedMetrics = selected }(selectedMetrics)
+	m := analysis.Metrics{Name: "f", Cyclomatic: 3, Cognitive: 2, BasisPaths: 3}
+	tests := []struct {
+		name     string
+		selected map[string]bool
+		present  []string
+		absent   []string
+	}{
+		{"all", nil, []string{"name", "cyclomatic", "cognitive", "basis_paths", "chepin"}, nil},
+		{"cyclomatic", map[string]bool{"cyclomatic": true},
+			[]string{"name", "file", "line", "lines", "cyclomatic", "ast_cyclomatic"},
+			[]string{"cognitive", "basis_paths", "chepin", "chepin_p", "statements"}},
+		{"chepin and paths", map[string]bool{"chepin": true, "paths": true},
+			[]string{"name", "chepin", "chepin_t", "basis_paths"},
+			[]string{"cyclomatic", "cognitive", "sccs", "variables"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selectedMetrics = test.selected
+			item, err := selectedFields(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]any
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatal(err)
+			}
+			for _, field := range test.present {
+				if _, ok := fields[field]; !ok {
+					t.Errorf("field %q missing from %s", field, data)
+				}
+			}
+			for _, field := range test.absent {
+				if _, ok := fields[field]; ok {
+					t.Errorf("field %q not left out of %s", field, data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// writeSplitGraphs writes the graphs of Result.SplitGraphs into dir as .dot
+// files and, when Graphviz is installed, renders each one to .svg.
+func writeSplitGraphs(dir string, graphs map[string]string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	dotPath, lookErr := exec.LookPath("dot")
+	for name, graph := range graphs {
+		path := filepath.Join(dir, name+".dot")
+		if err := os.WriteFile(path, []byte(graph), 0o644); err != nil {
+			return err
+		}
+		if lookErr != nil {
+			continue
+		}
+		if out, err := exec.Command(dotPath, "-Tsvg", "-o", filepath.Join(dir, name+".svg"), path).CombinedOutput(); err != nil {
+			return fmt.Errorf("rendering %s: %v: %s", path, err, out)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWriteSplitGraphs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "split")
+	graphs := map[string]string{
+		"f":         "digraph f {\n}\n",
+		"f_scope_1": "digraph f_scope_1 {\n}\n",
+	}
+	if err := writeSplitGraphs(dir, graphs); err != nil {
+		t.Fatal(err)
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.dot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, match := range matches {
+		names = append(names, filepath.Base(match))
+	}
+	if want := []string{"f.dot", "f_scope_1.dot"}; !slices.Equal(names, want) {
+		t.Errorf("wrote %v, want %v", names, want)
+	}
+	for name, graph := range graphs {
+		if got, err := os.ReadFile(filepath.Join(dir, name+".dot")); err != nil || string(got) != graph {
+			t.Errorf("%s.dot holds %q, error %v, want %q", name, got, err, graph)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// printSummary writes the -summary footer: cyclomatic complexity totals,
+// the most complex function, the total line count and the quartiles of the
+// Chepin scores of results.
+func printSummary(w io.Writer, results []*analysis.Result) {
+	fmt.Fprintln(w, strings.Repeat("-", 18))
+	fmt.Fprintln(w, "Summary:")
+	agg := aggregate(results)
+	fmt.Fprintf(w, "  Functions: %d\n", agg.Functions)
+	if len(results) == 0 {
+		return
+	}
+	most := results[0].Metrics
+	for _, result := range results[1:] {
+		if result.Metrics.Cyclomatic > most.Cyclomatic {
+			most = result.Metrics
+		}
+	}
+	fmt.Fprintf(w, "  Cyclomatic complexity: total %d, average %.2f, max %d (%s)\n",
+		agg.CyclomaticTotal, float64(agg.CyclomaticTotal)/float64(agg.Functions), most.Cyclomatic, most.Name)
+	fmt.Fprintf(w, "  Lines: %d\n", agg.Lines)
+
+	scores := make([]float64, 0, len(results))
+	for _, result := range results {
+		scores = append(scores, result.Metrics.Chepin)
+	}
+	slices.Sort(scores)
+	quantile := func(q float64) float64 {
+		return scores[int(q*float64(len(scores)-1)+0.5)]
+	}
+	fmt.Fprintf(w, "  Chepin scores: min %g, 25%% %g, median %g, 75%% %g, max %g (total %g)\n",
+		scores[0], quantile(0.25), quantile(0.5), quantile(0.75), scores[len(scores)-1], agg.ChepinTotal)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+func TestPrintSummary(t *testing.T) {
+	result := func(name string, cyclomatic, lines int, chepin float64) *analysis.Result {
+		return &analysis.Result{Metrics: analysis.Metrics{Name: name, Cyclomatic: cyclomatic, Lines: lines, Chepin: chepin}}
+	}
+	tests := []struct {
+		name    string
+		results []*analysis.Result
+		want    []string // lines after the header
+	}{
+		{"no functions", nil, []string{"  Functions: 0"}},
+		{"one function", []*analysis.Result{result("f", 3, 10, 4.5)}, []string{
+			"  Functions: 1",
+			"  Cyclomatic complexity: total 3, average 3.00, max 3 (f)",
+			"  Lines: 10",
+			"  Chepin scores: min 4.5, 25% 4.5, median 4.5, 75% 4.5, max 4.5 (total 4.5)",
+		}},
+		{"several functions", []*analysis.Result{
+			result("a", 1, 3, 2),
+			result("b", 5, 20, 10),
+			result("c", 5, 12, 1),
+			result("d", 2, 5, 7),
+			result("e", 1, 2, 3),
+		}, []string{
+			"  Functions: 5",
+			"  Cyclomatic complexity: total 14, average 2.80, max 5 (b)",
+			"  Lines: 42",
+			"  Chepin scores: min 1, 25% 2, median 3, 75% 7, max 10 (total 23)",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			printSummary(&out, test.results)
+			want := strings.Join(append([]string{strings.Repeat("-", 18), "Summary:"}, test.want...), "\n") + "\n"
+			if out.String() != want {
+				t.Errorf("summary:\n%s\nwant:\n%s", out.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestSyntaxErrorRecovery(t *testing.T) {
+	defer func(format string) { *outputFormat = format }(*outputFormat)
+	*outputFormat = "metrics-json"
+	tests := []struct {
+		name, src string
+		want      []string // analyzed functions
+		wantErr   bool
+	}{
+		{"valid", "package p\n\nfunc A() {}\n\nfunc B() {}\n", []string{"A", "B"}, false},
+		{"broken first", "package p\n\nfunc A() {\n\tx := [1\n}\n\nfunc B() {\n\ty()\n}\n", []string{"B"}, false},
+		// The parser gives up on the rest of the file after some errors,
+		// but what precedes them is still analyzed.
+		{"broken last", "package p\n\nfunc A() {\n\ty()\n}\n\nfunc B() {\n\tx := \n}\n", []string{"A"}, false},
+		{"error outside functions", "package p\n\nvar = 1\n\nfunc A() {}\n", []string{"A"}, false},
+		{"no package clause", "func A() {}\n", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := analyzeSource(context.Background(), "p.go", test.src)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("analyzeSource error %v, want error %v", err, test.wantErr)
+			}
+			var names []string
+			for _, result := range results {
+				names = append(names, result.Metrics.Name)
+			}
+			if !slices.Equal(names, test.want) {
+				t.Errorf("analyzed %v, want %v", names, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// tuiKey is a key press understood by the interactive browser.
+type tuiKey int
+
+const (
+	keyOther tuiKey = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyBack
+	keyQuit
+)
+
+// readKey reads one key press from a terminal in raw mode. Arrow keys arrive
+// as the escape sequences ESC [ A and ESC [ B; a lone ESC means back.
+func readKey(in *bufio.Reader) (tuiKey, error) {
+	b, err := in.ReadByte()
+	if err != nil {
+		return keyOther, err
+	}
+	switch b {
+	case '\r', '\n':
+		return keyEnter, nil
+	case 'k':
+		return keyUp, nil
+	case 'j':
+		return keyDown, nil
+	case 'q', 127:
+		return keyBack, nil
+	case 3: // Ctrl-C
+		return keyQuit, nil
+	case 0x1b:
+		if in.Buffered() == 0 {
+			return keyBack, nil
+		}
+		if next, _ := in.ReadByte(); next != '[' {
+			return keyOther, nil
+		}
+		switch code, _ := in.ReadByte(); code {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		}
+	}
+	return keyOther, nil
+}
+
+// runTUI lets the user browse results in the terminal: the arrow keys (or j
+// and k) move through the list of functions, enter shows the metrics,
+// findings and graph of the selected one, and q or escape goes back, or
+// quits from the list. Stdin and stdout must be a terminal.
+func runTUI(results []*analysis.Result) error {
+	if len(results) == 0 {
+		fmt.Println("No functions found.")
+		return nil
+	}
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+	fmt.Print("\x1b[?25l")
+	defer fmt.Print("\x1b[H\x1b[2J\x1b[?25h")
+
+	in := bufio.NewReader(os.Stdin)
+	list := listLines(results)
+	selected, offset := 0, 0
+	var detail []string // lines of the detail view, nil while in the list
+	for {
+		width, height, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil || height < 2 {
+			width, height = 80, 24
+		}
+		rows := height - 1
+		if detail == nil {
+			// Keep the selected row, which follows the header, on screen.
+			top := max(0, selected+2-rows)
+			drawScreen(os.Stdout, list[top:], selected+1-top, width, rows,
+				"↑/↓ move  enter details  q quit")
+		} else {
+			drawScreen(os.Stdout, detail[offset:], -1, width, rows,
+				fmt.Sprintf("↑/↓ scroll  q back  (%d/%d)", offset+1, len(detail)))
+		}
+
+		key, err := readKey(in)
+		if err == io.EOF || key == keyQuit {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		switch {
+		case detail == nil && key == keyUp:
+			selected = max(0, selected-1)
+		case detail == nil && key == keyDown:
+			selected = min(len(results)-1, selected+1)
+		case detail == nil && key == keyEnter:
+			detail, offset = detailLines(results[selected]), 0
+		case detail == nil && key == keyBack:
+			return nil
+		case key == keyUp:
+			offset = max(0, offset-1)
+		case key == keyDown:
+			offset = min(max(0, len(detail)-rows), offset+1)
+		case key == keyEnter, key == keyBack:
+			detail = nil
+		}
+	}
+}
+
+// drawScreen clears the terminal and draws up to rows lines, cut to width,
+// highlighting the line at index highlight, followed by a status line.
+func drawScreen(w io.Writer, lines []string, highlight, width, rows int, status string) {
+	fmt.Fprint(w, "\x1b[H\x1b[2J")
+	for i, line := range lines[:min(rows, len(lines))] {
+		if runes := []rune(line); len(runes) > width {
+			line = string(runes[:width])
+		}
+		if i == highlight {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		fmt.Fprint(w, line, "\r\n")
+	}
+	fmt.Fprint(w, "\x1b[7m"+status+"\x1b[0m")
+}
+
+// listLines returns the function list: a header followed by one row per
+// result.
+func listLines(results []*analysis.Result) []string {
+	lines := []string{fmt.Sprintf("%-30s %10s %9s %6s %8s  %s", "Function", "Cyclomatic", "Cognitive", "Chepin", "Findings", "Location")}
+	for _, result := range results {
+		m := result.Metrics
+		lines = append(lines, fmt.Sprintf("%-30s %10d %9d %6.1f %8d  %s:%d",
+			m.Name, m.Cyclomatic, m.Cognitive, m.Chepin, len(result.Findings), m.File, m.Line))
+	}
+	return lines
+}
+
+// detailLines returns the detail view of result: its metrics, findings and
+// DOT graph.
+func detailLines(result *analysis.Result) []string {
+	m := result.Metrics
+	lines := []string{
+		fmt.Sprintf("Function: %s (%s:%d, %d lines)", m.Name, m.File, m.Line, m.Lines),
+		strings.Repeat("-", 18),
+		fmt.Sprintf("Cyclomatic Complexity: %d", m.Cyclomatic),
+		fmt.Sprintf("Cognitive Complexity: %d", m.Cognitive),
+		fmt.Sprintf("Chepin score: %.1f (P=%d M=%d C=%d T=%d)", m.Chepin, m.ChepinP, m.ChepinM, m.ChepinC, m.ChepinT),
+		fmt.Sprintf("Max Expression Depth: %d", m.MaxExprDepth),
+		fmt.Sprintf("Basis paths: %d", m.BasisPaths),
+		fmt.Sprintf("Block sizes: largest %d, average %.2f, %d empty", m.LargestBlock, m.AverageBlockSize, m.EmptyBlocks),
+	}
+	for _, category := range analysis.CallCategories {
+		if n := m.Calls[category]; n > 0 {
+			lines = append(lines, fmt.Sprintf("Calls (%s): %d", category, n))
+		}
+	}
+	if m.Conversions > 0 {
+		lines = append(lines, fmt.Sprintf("Conversions: %d", m.Conversions))
+	}
+	if len(result.Findings) > 0 {
+		lines = append(lines, strings.Repeat("-", 18), "Findings:")
+		for _, finding := range result.Findings {
+			lines = append(lines, fmt.Sprintf("  %s: %s: %s [%s]", finding.Pos, finding.Severity, finding.Message, finding.RuleID))
+		}
+	}
+	lines = append(lines, strings.Repeat("-", 18), "DOT Format:")
+	return append(lines, strings.Split(strings.TrimRight(result.Dot, "\n"), "\n")...)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+// unhandledNodes collects the nodes without a rendering for -unhandled and
+// -validate.
+var unhandledNodes = analysis.UnhandledNodes{}
+
+// writeUnhandled writes the unhandled node types, most frequent first, as a
+// JSON array.
+func writeUnhandled(w io.Writer) error {
+	entries := []*analysis.UnhandledNode{}
+	for _, entry := range unhandledNodes {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Type < entries[j].Type
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
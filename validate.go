@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// printValidation writes the result of -validate for the given number of
+// functions: a pass line, or the node types that fell into an "Unhandled"
+// branch with their counts and example positions. It reports whether every
+// node type was handled.
+func printValidation(w io.Writer, functions int) bool {
+	if len(unhandledNodes) == 0 {
+		fmt.Fprintf(w, "Validation passed: %d functions, every node type handled\n", functions)
+		return true
+	}
+	types := make([]string, 0, len(unhandledNodes))
+	for typ := range unhandledNodes {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	fmt.Fprintf(w, "Validation failed: %d unhandled node types in %d functions\n", len(types), functions)
+	for _, typ := range types {
+		entry := unhandledNodes[typ]
+		fmt.Fprintf(w, "  %s: %d (%s)\n", typ, entry.Count, strings.Join(entry.Examples, ", "))
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"Rukatonoshi/PDG_Go_AVPB/analysis"
+)
+
+func TestValidate(t *testing.T) {
+	defer func(v, only bool, format string, nodes analysis.UnhandledNodes) {
+		*validate, *metricsOnly, *outputFormat, unhandledNodes = v, only, format, nodes
+	}(*validate, *metricsOnly, *outputFormat, unhandledNodes)
+	*validate, *metricsOnly, *outputFormat = true, true, "metrics-json"
+	tests := []struct {
+		name, body string
+		pass       bool
+		want       []string // lines of the report
+	}{
+		{"handled", "if !ok {\n\tx := a[i] + 1\n\tf(x)\n}\nswitch v {\ncase 1:\n}",
+			true, []string{"Validation passed: 1 functions, every node type handled"}},
+		{"unhandled", "if ok {\n\tf()\n}\nif v.(bool) {\n}",
+			false, []string{
+				"Validation failed: 1 unhandled node types in 1 functions",
+				"  *ast.TypeAssertExpr: 1 (snippet.go:4:4)",
+			}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unhandledNodes = analysis.UnhandledNodes{}
+			results, err := analyzeSource(context.Background(), "snippet.go", analysis.SnippetSource(test.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if pass := printValidation(&out, len(results)); pass != test.pass {
+				t.Errorf("validation passed = %v, want %v", pass, test.pass)
+			}
+			if want := strings.Join(test.want, "\n") + "\n"; out.String() != want {
+				t.Errorf("report:\n%s\nwant:\n%s", out.String(), want)
+			}
+		})
+	}
+}